@@ -67,4 +67,10 @@ func init() {
 
 	runCmd.Flags().String("repo-url", "", "Repository URL to scan (overrides config)")
 	viper.BindPFlag("repository.url", runCmd.Flags().Lookup("repo-url"))
+
+	runCmd.Flags().String("as-of", "", "Evaluate files as of this date (YYYY-MM-DD) and report instead of creating PRs")
+	viper.BindPFlag("forecast.as_of", runCmd.Flags().Lookup("as-of"))
+
+	runCmd.Flags().String("horizon", "", "Report the files becoming due within this period, e.g. 90d or 12w (no PRs are created)")
+	viper.BindPFlag("forecast.horizon", runCmd.Flags().Lookup("horizon"))
 }
@@ -0,0 +1,185 @@
+package assign
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// codeownersLocations are searched in order; the first file found is used.
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// ErrNoCodeowners is returned by LoadCodeowners when the repository has no CODEOWNERS file.
+var ErrNoCodeowners = errors.New("no CODEOWNERS file found")
+
+// sectionHeader matches GitLab section headers such as [Terraform], ^[Docs] or
+// [Security][2] @security-team, capturing the name and the default owners.
+var sectionHeader = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Codeowners holds the rules of a CODEOWNERS file. Rules of the same GitLab section (or of a
+// file without sections) follow last-match-wins; the owners of all sections are combined.
+type Codeowners struct {
+	Source string
+	rules  []codeownersRule
+}
+
+type codeownersRule struct {
+	section string
+	pattern string
+	owners  []string
+}
+
+// LoadCodeowners reads the CODEOWNERS file of a repository from .github/, the root, docs/ or
+// .gitlab/.
+func LoadCodeowners(repoRoot string) (*Codeowners, error) {
+	for _, location := range codeownersLocations {
+		content, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(location)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		codeowners, err := ParseCodeowners(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", location, err)
+		}
+		codeowners.Source = location
+		return codeowners, nil
+	}
+	return nil, fmt.Errorf("%w in %s", ErrNoCodeowners, strings.Join(codeownersLocations, ", "))
+}
+
+// ParseCodeowners parses CODEOWNERS content in GitHub or GitLab syntax.
+func ParseCodeowners(content []byte) (*Codeowners, error) {
+	c := &Codeowners{}
+	var section string
+	var defaults []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if m := sectionHeader.FindStringSubmatch(text); m != nil {
+			section = strings.ToLower(strings.TrimSpace(m[1]))
+			defaults = codeownersFields(m[2])
+			continue
+		}
+
+		fields := codeownersFields(text)
+		pattern, err := codeownersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = defaults
+		}
+		c.rules = append(c.rules, codeownersRule{section: section, pattern: pattern, owners: owners})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// codeownersFields splits a line on whitespace, keeping escaped spaces in paths and dropping
+// trailing comments.
+func codeownersFields(text string) []string {
+	var fields []string
+	for _, f := range strings.Fields(strings.ReplaceAll(text, `\ `, "\x00")) {
+		if strings.HasPrefix(f, "#") {
+			break
+		}
+		fields = append(fields, strings.ReplaceAll(f, "\x00", " "))
+	}
+	return fields
+}
+
+// codeownersPattern converts a gitignore-style CODEOWNERS path into a doublestar pattern.
+// Paths without a slash except at the end match at any depth, others are relative to the
+// repository root; a trailing slash only matches directory contents.
+func codeownersPattern(p string) (string, error) {
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	if p != "" && p != "*" && !strings.Contains(p, "/") {
+		p = "**/" + p
+	}
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || p == "*" {
+		p = "**"
+	} else if dirOnly {
+		p += "/**"
+	}
+	if !doublestar.ValidatePattern(p) {
+		return "", fmt.Errorf("invalid path pattern %q", p)
+	}
+	return p, nil
+}
+
+// Owners returns the owners of a repo-relative path: in every section, those of the last rule
+// matching the path or one of its parent directories.
+func (c *Codeowners) Owners(relPath string) []string {
+	matched := make(map[string][]string)
+	var sections []string
+	for _, rule := range c.rules {
+		if !matchesPathOrContents(rule.pattern, relPath) {
+			continue
+		}
+		if _, ok := matched[rule.section]; !ok {
+			sections = append(sections, rule.section)
+		}
+		matched[rule.section] = rule.owners
+	}
+
+	var owners []string
+	for _, section := range sections {
+		owners = appendUnique(owners, matched[section]...)
+	}
+	return owners
+}
+
+func matchesPathOrContents(pattern, relPath string) bool {
+	if ok, _ := doublestar.Match(pattern, relPath); ok {
+		return true
+	}
+	// A pattern naming a directory owns everything below it, except that dir/* only owns the
+	// files directly in dir
+	if strings.HasSuffix(pattern, "/*") {
+		return false
+	}
+	ok, _ := doublestar.Match(pattern+"/**", relPath)
+	return ok
+}
+
+// Assign combines the owners of files. Users (@name) become assignees and teams (@org/team) team
+// reviewers. Email addresses are mapped to usernames by username; those it returns an empty
+// string for are dropped.
+func (c *Codeowners) Assign(files []types.RecertCheckResult, username func(email string) string) types.AssignmentResult {
+	var result types.AssignmentResult
+	for _, f := range files {
+		for _, owner := range c.Owners(f.File.RepoPath()) {
+			name := strings.TrimPrefix(owner, "@")
+			switch {
+			case strings.HasPrefix(owner, "@") && strings.Contains(name, "/"):
+				result.TeamReviewers = appendUnique(result.TeamReviewers, name)
+			case !strings.HasPrefix(owner, "@") && strings.Contains(owner, "@"):
+				if name = username(owner); name != "" {
+					result.Assignees = appendUnique(result.Assignees, name)
+				}
+			default:
+				result.Assignees = appendUnique(result.Assignees, name)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package assign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCodeowners_Owners(t *testing.T) {
+	c, err := ParseCodeowners([]byte(`# Default owners
+*                       @platform-lead
+*.tf                    @org/terraform   # Terraform modules
+/terraform/prod/        @org/infra alice@example.com
+docs/*                  @writer
+apps/                   @org/apps
+/terraform/prod/iam.tf  @security
+My\ Folder/             @bob
+`))
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		"README.md":                  {"@platform-lead"},
+		"modules/vpc/main.tf":        {"@org/terraform"},
+		"terraform/prod/main.tf":     {"@org/infra", "alice@example.com"},
+		"terraform/prod/net/vpc.tf":  {"@org/infra", "alice@example.com"},
+		"terraform/prod/iam.tf":      {"@security"},
+		"docs/index.md":              {"@writer"},
+		"docs/guides/setup.md":       {"@platform-lead"},
+		"services/apps/web/app.yaml": {"@org/apps"},
+		"My Folder/notes.txt":        {"@bob"},
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, c.Owners(path), path)
+	}
+}
+
+func TestCodeowners_GitLabSections(t *testing.T) {
+	c, err := ParseCodeowners([]byte(`*.tf @infra
+
+[Security][2] @security-team
+/terraform/prod/
+*.rego @policy
+
+^[Docs] @writers
+docs/
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"@infra", "@security-team"}, c.Owners("terraform/prod/main.tf"))
+	assert.Equal(t, []string{"@policy"}, c.Owners("policies/deny.rego"))
+	assert.Equal(t, []string{"@writers"}, c.Owners("docs/README.md"))
+	assert.Empty(t, c.Owners("Makefile"))
+}
+
+func TestCodeowners_Assign(t *testing.T) {
+	c, err := ParseCodeowners([]byte(`*.tf @alice @org/infra
+*.yaml @bob carol@example.com @org/infra
+`))
+	require.NoError(t, err)
+
+	files := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/tmp/scan/main.tf", RelPath: "main.tf"}},
+		{File: types.FileInfo{Path: "/tmp/scan/app.yaml", RelPath: "app.yaml"}},
+		{File: types.FileInfo{Path: "/tmp/scan/README.md", RelPath: "README.md"}},
+	}
+	result := c.Assign(files, func(email string) string {
+		return map[string]string{"carol@example.com": "carol"}[email]
+	})
+	assert.Equal(t, []string{"alice", "bob", "carol"}, result.Assignees)
+	assert.Equal(t, []string{"org/infra"}, result.TeamReviewers)
+
+	// Unresolved emails are dropped
+	result = c.Assign(files, func(string) string { return "" })
+	assert.Equal(t, []string{"alice", "bob"}, result.Assignees)
+}
+
+func TestLoadCodeowners(t *testing.T) {
+	root := t.TempDir()
+	_, err := LoadCodeowners(root)
+	assert.ErrorIs(t, err, ErrNoCodeowners)
+
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0644))
+	}
+	write("docs/CODEOWNERS", "* @docs\n")
+	write("CODEOWNERS", "* @root\n")
+	c, err := LoadCodeowners(root)
+	require.NoError(t, err)
+	assert.Equal(t, "CODEOWNERS", c.Source)
+
+	write(".github/CODEOWNERS", "* @github\n")
+	c, err = LoadCodeowners(root)
+	require.NoError(t, err)
+	assert.Equal(t, ".github/CODEOWNERS", c.Source)
+	assert.Equal(t, []string{"@github"}, c.Owners("main.tf"))
+}
+
+func TestResolver_Codeowners(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "CODEOWNERS"), []byte("/terraform/ @org/infra\n"), 0644))
+
+	r := NewResolver(config.AssignmentConfig{Strategy: "codeowners", FallbackAssignees: []string{"devops-team"}}, nil, zap.NewNop())
+	require.NoError(t, r.SetRepoRoot(root))
+
+	result, err := r.Resolve(context.Background(), types.FileGroup{Files: []types.RecertCheckResult{
+		{File: types.FileInfo{RelPath: "terraform/main.tf"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org/infra"}, result.TeamReviewers)
+	assert.Empty(t, result.Assignees)
+
+	result, err = r.Resolve(context.Background(), types.FileGroup{Files: []types.RecertCheckResult{
+		{File: types.FileInfo{RelPath: "k8s/app.yaml"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devops-team"}, result.Assignees)
+
+	// Without the strategy no CODEOWNERS file is needed
+	r = NewResolver(config.AssignmentConfig{Strategy: "static"}, nil, zap.NewNop())
+	assert.NoError(t, r.SetRepoRoot(t.TempDir()))
+}
+
+func TestResolver_CodeownersEmails(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "CODEOWNERS"), []byte("* alice@example.com bob@example.com\n"), 0644))
+	files := types.FileGroup{Files: []types.RecertCheckResult{{File: types.FileInfo{RelPath: "main.tf"}}}}
+
+	r := NewResolver(config.AssignmentConfig{Strategy: "codeowners", FallbackAssignees: []string{"devops-team"}}, nil, zap.NewNop())
+	require.NoError(t, r.SetRepoRoot(root))
+	r.SetIdentities(NewIdentities(nil, map[string]string{"alice@example.com": "alice"}, zap.NewNop()))
+	result, err := r.Resolve(context.Background(), files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, result.Assignees)
+
+	// Without a user lookup the emails are dropped and the fallback applies
+	r = NewResolver(config.AssignmentConfig{Strategy: "codeowners", FallbackAssignees: []string{"devops-team"}}, nil, zap.NewNop())
+	require.NoError(t, r.SetRepoRoot(root))
+	result, err = r.Resolve(context.Background(), files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devops-team"}, result.Assignees)
+}
+
+func TestResolver_CodeownersMissing(t *testing.T) {
+	r := NewResolver(config.AssignmentConfig{
+		Strategy:          "composite",
+		FallbackAssignees: []string{"devops-team"},
+		Rules: []config.AssignmentRule{
+			{Pattern: "terraform/**", Strategy: "codeowners", FallbackAssignees: []string{"infra-team"}},
+		},
+	}, nil, zap.NewNop())
+	require.NoError(t, r.SetRepoRoot(t.TempDir()))
+
+	result, err := r.Resolve(context.Background(), types.FileGroup{Files: []types.RecertCheckResult{
+		{File: types.FileInfo{RelPath: "terraform/main.tf"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra-team"}, result.Assignees)
+}
@@ -0,0 +1,96 @@
+package assign
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// AuthorLookup resolves the provider account of a commit author, as provider.GitProvider does.
+type AuthorLookup interface {
+	ResolveCommitAuthor(ctx context.Context, hash, email string) (string, error)
+}
+
+// Identities maps commit authors to provider usernames: through the provider first, then
+// through an email to username directory. Results, including failures, are cached for the run.
+type Identities struct {
+	lookup    AuthorLookup
+	directory map[string]string // Lowercase email to username
+	logger    *zap.Logger
+	cache     map[string]string
+}
+
+func NewIdentities(lookup AuthorLookup, directory map[string]string, logger *zap.Logger) *Identities {
+	normalized := make(map[string]string, len(directory))
+	for email, username := range directory {
+		normalized[strings.ToLower(email)] = username
+	}
+	return &Identities{
+		lookup:    lookup,
+		directory: normalized,
+		logger:    logger,
+		cache:     make(map[string]string),
+	}
+}
+
+// LoadUserDirectory reads a YAML file mapping commit emails to usernames.
+func LoadUserDirectory(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user directory: %w", err)
+	}
+	var directory map[string]string
+	if err := yaml.Unmarshal(content, &directory); err != nil {
+		return nil, fmt.Errorf("failed to parse user directory %s: %w", path, err)
+	}
+	return directory, nil
+}
+
+// EmailUsername returns the username of the account with the given email, or an empty string
+// when it can't be resolved.
+func (i *Identities) EmailUsername(ctx context.Context, email string) string {
+	return i.Username(ctx, types.FileInfo{CommitEmail: email})
+}
+
+// Username returns the username of the author of the file's last commit, or an empty string
+// when it can't be resolved. Unresolved commits are logged once.
+func (i *Identities) Username(ctx context.Context, file types.FileInfo) string {
+	email := strings.ToLower(file.CommitEmail)
+	key := email
+	if key == "" {
+		key = file.CommitHash
+	}
+
+	if key == "" {
+		i.logger.Warn("could not resolve commit author without commit or email", zap.String("author", file.CommitAuthor))
+		return ""
+	}
+	if username, ok := i.cache[key]; ok {
+		return username
+	}
+
+	var username string
+	if i.lookup != nil {
+		var err error
+		username, err = i.lookup.ResolveCommitAuthor(ctx, file.CommitHash, file.CommitEmail)
+		if err != nil {
+			i.logger.Debug("provider failed to resolve commit author", zap.String("commit", file.CommitHash), zap.Error(err))
+		}
+	}
+	if username == "" && email != "" {
+		username = i.directory[email]
+	}
+	if username == "" {
+		i.logger.Warn("could not resolve commit author to a username",
+			zap.String("commit", file.CommitHash),
+			zap.String("author", file.CommitAuthor),
+			zap.String("email", file.CommitEmail))
+	}
+	i.cache[key] = username
+	return username
+}
@@ -0,0 +1,80 @@
+package assign
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeLookup resolves commits by hash and counts the lookups.
+type fakeLookup struct {
+	logins map[string]string
+	calls  int
+}
+
+func (l *fakeLookup) ResolveCommitAuthor(_ context.Context, hash, _ string) (string, error) {
+	l.calls++
+	if hash == "broken" {
+		return "", errors.New("API unavailable")
+	}
+	return l.logins[hash], nil
+}
+
+func TestIdentities_Username(t *testing.T) {
+	lookup := &fakeLookup{logins: map[string]string{"abc123": "jdoe"}}
+	identities := NewIdentities(lookup, map[string]string{"Ops@Example.com": "ops-bot"}, zap.NewNop())
+	ctx := context.Background()
+
+	jane := types.FileInfo{CommitHash: "abc123", CommitAuthor: "Jane Doe", CommitEmail: "jane@example.com"}
+	assert.Equal(t, "jdoe", identities.Username(ctx, jane))
+	// Other commits of the same author are served from the cache
+	jane.CommitHash = "def456"
+	assert.Equal(t, "jdoe", identities.Username(ctx, jane))
+	assert.Equal(t, 1, lookup.calls)
+
+	ops := types.FileInfo{CommitHash: "broken", CommitAuthor: "Ops", CommitEmail: "ops@example.com"}
+	assert.Equal(t, "ops-bot", identities.Username(ctx, ops))
+
+	unknown := types.FileInfo{CommitHash: "fff000", CommitAuthor: "Former Employee", CommitEmail: "gone@example.com"}
+	assert.Empty(t, identities.Username(ctx, unknown))
+	assert.Empty(t, identities.Username(ctx, unknown))
+	assert.Equal(t, 3, lookup.calls)
+}
+
+func TestLoadUserDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("jane@example.com: jdoe\nops@example.com: ops-bot\n"), 0644))
+
+	directory, err := LoadUserDirectory(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"jane@example.com": "jdoe", "ops@example.com": "ops-bot"}, directory)
+
+	_, err = LoadUserDirectory(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read user directory")
+}
+
+func TestResolver_LastCommitterIdentities(t *testing.T) {
+	r := NewResolver(config.AssignmentConfig{Strategy: "last_committer", FallbackAssignees: []string{"devops-team"}}, nil, zap.NewNop())
+	r.SetIdentities(NewIdentities(&fakeLookup{logins: map[string]string{"abc123": "jdoe"}}, nil, zap.NewNop()))
+
+	result, err := r.Resolve(context.Background(), types.FileGroup{Files: []types.RecertCheckResult{
+		{File: types.FileInfo{CommitHash: "abc123", CommitAuthor: "Jane Doe", LastModified: time.Now()}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jdoe"}, result.Assignees)
+
+	result, err = r.Resolve(context.Background(), types.FileGroup{Files: []types.RecertCheckResult{
+		{File: types.FileInfo{CommitHash: "fff000", CommitAuthor: "Former Employee", LastModified: time.Now()}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devops-team"}, result.Assignees)
+}
@@ -2,18 +2,24 @@ package assign
 
 import (
 	"context"
-	"time"
+	"errors"
+	"fmt"
 
 	"github.com/baldator/iac-recert-engine/internal/config"
 	"github.com/baldator/iac-recert-engine/internal/plugin"
 	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/bmatcuk/doublestar/v4"
 	"go.uber.org/zap"
 )
 
 type Resolver struct {
-	cfg    config.AssignmentConfig
-	logger *zap.Logger
-	pm     *plugin.Manager
+	cfg        config.AssignmentConfig
+	logger     *zap.Logger
+	pm         *plugin.Manager
+	codeowners *Codeowners
+	identities *Identities
+	roundRobin *RoundRobin
+	preview    bool // Set by Preview: round_robin reports its pool instead of rotating
 }
 
 func NewResolver(cfg config.AssignmentConfig, pm *plugin.Manager, logger *zap.Logger) *Resolver {
@@ -24,64 +30,171 @@ func NewResolver(cfg config.AssignmentConfig, pm *plugin.Manager, logger *zap.Lo
 	}
 }
 
-func (r *Resolver) Resolve(ctx context.Context, group types.FileGroup) (types.AssignmentResult, error) {
-	r.logger.Debug("resolving assignment for group", zap.String("group_id", group.ID), zap.String("strategy", group.Strategy), zap.Int("files", len(group.Files)))
+// SetRepoRoot loads the CODEOWNERS file of the cloned repository when the codeowners strategy is
+// used. The repository is only cloned once the run starts, so it is not passed to NewResolver.
+// Without a CODEOWNERS file the strategy assigns the fallback assignees.
+func (r *Resolver) SetRepoRoot(root string) error {
+	r.codeowners = nil
+	if !r.usesStrategy("codeowners") {
+		return nil
+	}
+	codeowners, err := LoadCodeowners(root)
+	if errors.Is(err, ErrNoCodeowners) {
+		r.logger.Warn("codeowners strategy configured but no CODEOWNERS file found, using fallback assignees", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("loaded CODEOWNERS", zap.String("source", codeowners.Source), zap.Int("rules", len(codeowners.rules)))
+	r.codeowners = codeowners
+	return nil
+}
+
+// SetIdentities makes last_committer assign the username of the last commit's author instead of
+// its git display name, falling back when it can't be resolved.
+func (r *Resolver) SetIdentities(identities *Identities) {
+	r.identities = identities
+}
 
-	// Determine strategy for this group
-	// If composite, we need to match rules.
-	// But FileGroup might contain files from different patterns if strategy was "single_pr".
-	// However, usually we want to assign based on the group content.
+// SetRoundRobin sets the rotation used by the round_robin strategy.
+func (r *Resolver) SetRoundRobin(rr *RoundRobin) {
+	r.roundRobin = rr
+}
+
+// UsesStrategy reports whether the strategy is used, directly or by a composite rule.
+func (r *Resolver) UsesStrategy(strategy string) bool {
+	return r.usesStrategy(strategy)
+}
 
-	// If strategy is "composite", we iterate rules and find the first one that matches the group.
-	// But matching a group to a pattern is tricky if the group has mixed files.
-	// Assuming groups are homogeneous or we use the first file?
-	// Or we use the group ID if it contains pattern info?
-	// Let's assume we check the files in the group.
+func (r *Resolver) usesStrategy(strategy string) bool {
+	if r.cfg.Strategy == strategy {
+		return true
+	}
+	for _, rule := range r.cfg.Rules {
+		if r.cfg.Strategy == "composite" && rule.Strategy == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// Preview resolves the owners of files that are not due yet. The round_robin strategy, which
+// only picks a member when a PR is opened, returns all available members.
+func (r *Resolver) Preview(ctx context.Context, group types.FileGroup) (types.AssignmentResult, error) {
+	preview := *r
+	preview.preview = true
+	return preview.Resolve(ctx, group)
+}
+
+func (r *Resolver) Resolve(ctx context.Context, group types.FileGroup) (types.AssignmentResult, error) {
+	r.logger.Debug("resolving assignment for group", zap.String("group_id", group.ID), zap.String("strategy", group.Strategy), zap.Int("files", len(group.Files)))
 
 	strategy := r.cfg.Strategy
 	r.logger.Debug("using assignment strategy", zap.String("strategy", strategy))
 
+	var result types.AssignmentResult
+	var err error
 	if strategy == "composite" {
-		r.logger.Debug("composite strategy not fully implemented, using fallback assignees")
-		// Find matching rule
-		// We need to check if ANY file in the group matches the rule pattern?
-		// Or ALL files?
-		// Usually, we want to find a rule that covers the files.
-		// If files are mixed, composite strategy is hard.
-		// But let's assume we use the first file to determine the rule for the whole PR.
-		if len(group.Files) > 0 {
-			// firstFile := group.Files[0].File.Path
-			// We need to match firstFile against rule patterns.
-			// We need doublestar again? Or just simple match?
-			// Config says "pattern: terraform/prod/**".
-			// We should use doublestar.
-			// But I don't want to import doublestar here if I can avoid it.
-			// But I probably should.
-			// Let's skip complex matching for now and assume simple prefix or exact match?
-			// No, the spec says "pattern".
-
-			// For now, let's just fallback to default if composite.
+		result, err = r.resolveComposite(ctx, group)
+	} else {
+		result, err = r.resolveStrategy(ctx, strategy, r.cfg.PluginName, r.cfg.FallbackAssignees, group, group.Files)
+	}
+	if err != nil {
+		return types.AssignmentResult{}, err
+	}
+	result.Reviewers = appendUnique(result.Reviewers, r.cfg.Reviewers...)
+	result.TeamReviewers = appendUnique(result.TeamReviewers, r.cfg.TeamReviewers...)
+	return result, nil
+}
+
+// resolveComposite assigns each file of the group through the first rule whose pattern matches
+// its repo-relative path, and merges the assignees and reviewers of all rules that fired. Files
+// no rule matches, or a group for which no rule yields assignees, get the fallback assignees.
+func (r *Resolver) resolveComposite(ctx context.Context, group types.FileGroup) (types.AssignmentResult, error) {
+	matched := make([][]types.RecertCheckResult, len(r.cfg.Rules))
+	var unmatched []types.RecertCheckResult
+	for _, f := range group.Files {
+		i, err := r.matchRule(f.File)
+		if err != nil {
+			return types.AssignmentResult{}, err
+		}
+		if i < 0 {
+			unmatched = append(unmatched, f)
+			continue
+		}
+		matched[i] = append(matched[i], f)
+	}
+
+	var result types.AssignmentResult
+	for i, files := range matched {
+		if len(files) == 0 {
+			continue
+		}
+		rule := r.cfg.Rules[i]
+		ruleResult, err := r.resolveStrategy(ctx, rule.Strategy, rule.Plugin, rule.FallbackAssignees, group, files)
+		if err != nil {
+			return types.AssignmentResult{}, fmt.Errorf("assignment rule %q failed: %w", rule.Pattern, err)
+		}
+		r.logger.Debug("assignment rule matched", zap.String("rule", rule.Pattern), zap.String("strategy", rule.Strategy), zap.Int("files", len(files)), zap.Strings("assignees", ruleResult.Assignees))
+		result.Assignees = appendUnique(result.Assignees, ruleResult.Assignees...)
+		result.Reviewers = appendUnique(result.Reviewers, ruleResult.Reviewers...)
+		result.Reviewers = appendUnique(result.Reviewers, rule.Reviewers...)
+		result.TeamReviewers = appendUnique(result.TeamReviewers, ruleResult.TeamReviewers...)
+		result.TeamReviewers = appendUnique(result.TeamReviewers, rule.TeamReviewers...)
+		result.Rules = append(result.Rules, rule.Pattern)
+		result.RoundRobin = append(result.RoundRobin, ruleResult.RoundRobin...)
+	}
+
+	if len(unmatched) > 0 || (len(result.Assignees) == 0 && len(result.TeamReviewers) == 0) {
+		r.logger.Debug("using fallback assignees for files without a matching rule", zap.Int("files", len(unmatched)), zap.Strings("assignees", r.cfg.FallbackAssignees))
+		result.Assignees = appendUnique(result.Assignees, r.cfg.FallbackAssignees...)
+	}
+
+	r.logger.Debug("assigned using composite strategy", zap.Strings("assignees", result.Assignees), zap.Strings("rules", result.Rules))
+	return result, nil
+}
+
+// matchRule returns the index of the first rule matching the file, or -1.
+func (r *Resolver) matchRule(file types.FileInfo) (int, error) {
+	relPath := file.RepoPath()
+	for i, rule := range r.cfg.Rules {
+		ok, err := doublestar.Match(rule.Pattern, relPath)
+		if err != nil {
+			return -1, fmt.Errorf("invalid assignment rule pattern %q: %w", rule.Pattern, err)
+		}
+		if ok {
+			return i, nil
 		}
 	}
+	return -1, nil
+}
 
+// resolveStrategy assigns files of the group, all of them or those of a composite rule, with a
+// single strategy. Strategies that find nobody, such as last_committer without commit authors,
+// fall back to the given assignees.
+func (r *Resolver) resolveStrategy(ctx context.Context, strategy, pluginName string, fallback []string, group types.FileGroup, files []types.RecertCheckResult) (types.AssignmentResult, error) {
 	switch strategy {
 	case "static":
 		result := types.AssignmentResult{
-			Assignees: r.cfg.FallbackAssignees,
+			Assignees: fallback,
 		}
 		r.logger.Debug("assigned using static strategy", zap.Strings("assignees", result.Assignees))
 		return result, nil
 	case "last_committer":
 		// Find the most recent committer
 		var lastAuthor string
-		var lastTime time.Time
-		for _, f := range group.Files {
-			if f.File.CommitAuthor != "" && f.File.LastModified.After(lastTime) {
+		var lastFile types.FileInfo
+		for _, f := range files {
+			if f.File.CommitAuthor != "" && f.File.LastModified.After(lastFile.LastModified) {
 				lastAuthor = f.File.CommitAuthor
-				lastTime = f.File.LastModified
+				lastFile = f.File
 			}
 		}
-		var assignees []string
+		if lastAuthor != "" && r.identities != nil {
+			lastAuthor = r.identities.Username(ctx, lastFile)
+		}
+		assignees := fallback
 		if lastAuthor != "" {
 			assignees = []string{lastAuthor}
 		}
@@ -90,30 +203,91 @@ func (r *Resolver) Resolve(ctx context.Context, group types.FileGroup) (types.As
 		}
 		r.logger.Debug("assigned using last_committer strategy", zap.Strings("assignees", result.Assignees))
 		return result, nil
+	case "codeowners":
+		if r.codeowners == nil {
+			r.logger.Debug("no CODEOWNERS loaded, using fallback assignees", zap.Strings("assignees", fallback))
+			return types.AssignmentResult{Assignees: fallback}, nil
+		}
+		result := r.codeowners.Assign(files, func(email string) string {
+			if r.identities == nil {
+				r.logger.Warn("dropping CODEOWNERS email owner without a user lookup", zap.String("email", email))
+				return ""
+			}
+			username := r.identities.EmailUsername(ctx, email)
+			if username == "" {
+				r.logger.Warn("dropping CODEOWNERS email owner that could not be resolved to a username", zap.String("email", email))
+			}
+			return username
+		})
+		if len(result.Assignees) == 0 && len(result.TeamReviewers) == 0 {
+			result.Assignees = fallback
+		}
+		r.logger.Debug("assigned using codeowners strategy", zap.Strings("assignees", result.Assignees), zap.Strings("team_reviewers", result.TeamReviewers))
+		return result, nil
+	case "round_robin":
+		if r.roundRobin == nil {
+			return types.AssignmentResult{}, fmt.Errorf("round robin rotation not configured")
+		}
+		if r.preview {
+			result := types.AssignmentResult{Assignees: r.roundRobin.Pool()}
+			r.logger.Debug("previewed round_robin pool", zap.Strings("assignees", result.Assignees))
+			return result, nil
+		}
+		// The rotation works on the whole group, whose branch identifies its PR
+		decision, err := r.roundRobin.Assign(ctx, group)
+		if err != nil {
+			return types.AssignmentResult{}, err
+		}
+		result := types.AssignmentResult{Assignees: fallback, RoundRobin: []types.RoundRobinDecision{decision}}
+		if decision.Member != "" {
+			result.Assignees = []string{decision.Member}
+		}
+		r.logger.Debug("assigned using round_robin strategy", zap.Strings("assignees", result.Assignees), zap.String("reason", decision.Reason))
+		return result, nil
 	case "plugin":
 		// Call plugin
-		plugin, err := r.pm.GetAssignmentPlugin(r.cfg.PluginName)
+		plugin, err := r.pm.GetAssignmentPlugin(pluginName)
 		if err != nil {
-			r.logger.Error("failed to get assignment plugin", zap.String("plugin", r.cfg.PluginName), zap.Error(err))
+			r.logger.Error("failed to get assignment plugin", zap.String("plugin", pluginName), zap.Error(err))
 			return types.AssignmentResult{}, err
 		}
 		// Extract FileInfo from RecertCheckResult
-		var files []types.FileInfo
-		for _, f := range group.Files {
-			files = append(files, f.File)
+		var infos []types.FileInfo
+		for _, f := range files {
+			infos = append(infos, f.File)
 		}
-		result, err := plugin.Resolve(files)
+		result, err := plugin.Resolve(infos)
 		if err != nil {
 			r.logger.Error("plugin resolve failed", zap.Error(err))
 			return types.AssignmentResult{}, err
 		}
+		if len(result.Assignees) == 0 {
+			result.Assignees = fallback
+		}
 		r.logger.Debug("assigned using plugin strategy", zap.Strings("assignees", result.Assignees))
 		return result, nil
 	default:
 		result := types.AssignmentResult{
-			Assignees: r.cfg.FallbackAssignees,
+			Assignees: fallback,
 		}
 		r.logger.Debug("assigned using fallback strategy", zap.Strings("assignees", result.Assignees))
 		return result, nil
 	}
 }
+
+// appendUnique appends the values not already in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -70,6 +70,114 @@ func TestResolver_Resolve(t *testing.T) {
 				Assignees: []string{"user2"},
 			},
 		},
+		{
+			name: "composite strategy with mixed group",
+			cfg: config.AssignmentConfig{
+				Strategy: "composite",
+				Rules: []config.AssignmentRule{
+					{Pattern: "terraform/prod/**", Strategy: "static", FallbackAssignees: []string{"infra-team"}},
+					{Pattern: "terraform/**", Strategy: "static", FallbackAssignees: []string{"terraform-team"}},
+					{Pattern: "k8s/**", Strategy: "last_committer", FallbackAssignees: []string{"k8s-team"}},
+				},
+				FallbackAssignees: []string{"devops-team"},
+			},
+			group: types.FileGroup{
+				Files: []types.RecertCheckResult{
+					{File: types.FileInfo{Path: "/tmp/scan/terraform/prod/main.tf", RelPath: "terraform/prod/main.tf"}},
+					{File: types.FileInfo{Path: "/tmp/scan/k8s/app.yaml", RelPath: "k8s/app.yaml", CommitAuthor: "bob", LastModified: time.Now()}},
+					{File: types.FileInfo{Path: "/tmp/scan/docs/README.md", RelPath: "docs/README.md"}},
+					{File: types.FileInfo{Path: "/tmp/scan/terraform/dev/main.tf", RelPath: "terraform/dev/main.tf"}},
+				},
+			},
+			expectedResult: types.AssignmentResult{
+				Assignees: []string{"infra-team", "terraform-team", "bob", "devops-team"},
+				Rules:     []string{"terraform/prod/**", "terraform/**", "k8s/**"},
+			},
+		},
+		{
+			name: "composite strategy with rule fallback",
+			cfg: config.AssignmentConfig{
+				Strategy: "composite",
+				Rules: []config.AssignmentRule{
+					{Pattern: "terraform/prod/**", Strategy: "static", FallbackAssignees: []string{"infra-team"}},
+					{Pattern: "terraform/**", Strategy: "static", FallbackAssignees: []string{"terraform-team"}},
+					{Pattern: "k8s/**", Strategy: "last_committer", FallbackAssignees: []string{"k8s-team"}},
+				},
+				FallbackAssignees: []string{"devops-team"},
+			},
+			group: types.FileGroup{
+				Files: []types.RecertCheckResult{
+					{File: types.FileInfo{Path: "/tmp/scan/k8s/app.yaml", RelPath: "k8s/app.yaml"}},
+				},
+			},
+			expectedResult: types.AssignmentResult{
+				Assignees: []string{"k8s-team"},
+				Rules:     []string{"k8s/**"},
+			},
+		},
+		{
+			name: "composite strategy without matching rule",
+			cfg: config.AssignmentConfig{
+				Strategy: "composite",
+				Rules: []config.AssignmentRule{
+					{Pattern: "terraform/prod/**", Strategy: "static", FallbackAssignees: []string{"infra-team"}},
+					{Pattern: "terraform/**", Strategy: "static", FallbackAssignees: []string{"terraform-team"}},
+					{Pattern: "k8s/**", Strategy: "last_committer", FallbackAssignees: []string{"k8s-team"}},
+				},
+				FallbackAssignees: []string{"devops-team"},
+			},
+			group: types.FileGroup{
+				Files: []types.RecertCheckResult{
+					{File: types.FileInfo{Path: "/tmp/scan/docs/README.md", RelPath: "docs/README.md"}},
+				},
+			},
+			expectedResult: types.AssignmentResult{
+				Assignees: []string{"devops-team"},
+			},
+		},
+		{
+			name: "static strategy with reviewers",
+			cfg: config.AssignmentConfig{
+				Strategy:          "static",
+				FallbackAssignees: []string{"user1"},
+				Reviewers:         []string{"lead"},
+				TeamReviewers:     []string{"org/security"},
+			},
+			group: types.FileGroup{
+				Files: []types.RecertCheckResult{
+					{File: types.FileInfo{Path: "file1"}},
+				},
+			},
+			expectedResult: types.AssignmentResult{
+				Assignees:     []string{"user1"},
+				Reviewers:     []string{"lead"},
+				TeamReviewers: []string{"org/security"},
+			},
+		},
+		{
+			name: "composite strategy with rule reviewers",
+			cfg: config.AssignmentConfig{
+				Strategy: "composite",
+				Rules: []config.AssignmentRule{
+					{Pattern: "terraform/prod/**", Strategy: "static", FallbackAssignees: []string{"infra-team"}, Reviewers: []string{"cab"}, TeamReviewers: []string{"org/infra"}},
+					{Pattern: "k8s/**", Strategy: "last_committer", FallbackAssignees: []string{"k8s-team"}, Reviewers: []string{"cab", "sre-lead"}},
+				},
+				FallbackAssignees: []string{"devops-team"},
+				TeamReviewers:     []string{"org/security"},
+			},
+			group: types.FileGroup{
+				Files: []types.RecertCheckResult{
+					{File: types.FileInfo{Path: "/tmp/scan/terraform/prod/main.tf", RelPath: "terraform/prod/main.tf"}},
+					{File: types.FileInfo{Path: "/tmp/scan/k8s/app.yaml", RelPath: "k8s/app.yaml", CommitAuthor: "bob", LastModified: time.Now()}},
+				},
+			},
+			expectedResult: types.AssignmentResult{
+				Assignees:     []string{"infra-team", "bob"},
+				Reviewers:     []string{"cab", "sre-lead"},
+				TeamReviewers: []string{"org/infra", "org/security"},
+				Rules:         []string{"terraform/prod/**", "k8s/**"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
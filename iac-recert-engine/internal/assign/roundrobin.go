@@ -0,0 +1,186 @@
+package assign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"go.uber.org/zap"
+)
+
+// BranchNamer returns the branch a group's PR is opened from.
+type BranchNamer func(group types.FileGroup) string
+
+// OpenPRChecker reports whether a recertification PR is open for a branch.
+type OpenPRChecker func(ctx context.Context, branch string) (bool, error)
+
+// RoundRobin rotates assignments over the configured members. Each group goes to the available
+// member with the fewest assignments relative to their weight, skipping members at the open PR
+// cap. The assignment counts and the branches of open PRs per member are persisted between runs,
+// so the rotation continues and a group whose PR is still open keeps its member.
+type RoundRobin struct {
+	cfg         config.RoundRobinConfig
+	branch      BranchNamer
+	isOpen      OpenPRChecker
+	logger      *zap.Logger
+	state       roundRobinState
+	loaded      bool
+	unavailable map[string]bool
+}
+
+// roundRobinState is the layout of the state file.
+type roundRobinState struct {
+	Members map[string]*memberState `json:"members"`
+}
+
+type memberState struct {
+	Assigned int      `json:"assigned"`
+	Open     []string `json:"open,omitempty"` // Branches of PRs assigned to the member, open at the last check
+}
+
+func NewRoundRobin(cfg config.RoundRobinConfig, branch BranchNamer, isOpen OpenPRChecker, logger *zap.Logger) *RoundRobin {
+	unavailable := make(map[string]bool)
+	for _, name := range cfg.Unavailable {
+		unavailable[name] = true
+	}
+	return &RoundRobin{cfg: cfg, branch: branch, isOpen: isOpen, logger: logger, unavailable: unavailable}
+}
+
+// load reads the state file and drops the branches whose PRs are no longer open.
+func (rr *RoundRobin) load(ctx context.Context) error {
+	if rr.loaded {
+		return nil
+	}
+	rr.state = roundRobinState{Members: make(map[string]*memberState)}
+	content, err := os.ReadFile(rr.cfg.StateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read round robin state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &rr.state); err != nil {
+			return fmt.Errorf("failed to parse round robin state %s: %w", rr.cfg.StateFile, err)
+		}
+		if rr.state.Members == nil {
+			rr.state.Members = make(map[string]*memberState)
+		}
+	}
+
+	for name, member := range rr.state.Members {
+		var open []string
+		for _, branch := range member.Open {
+			ok, err := rr.isOpen(ctx, branch)
+			if err != nil {
+				return fmt.Errorf("failed to check PR of %s: %w", branch, err)
+			}
+			if ok {
+				open = append(open, branch)
+			}
+		}
+		member.Open = open
+		rr.logger.Debug("loaded round robin member", zap.String("member", name), zap.Int("assigned", member.Assigned), zap.Int("open", len(open)))
+	}
+	rr.loaded = true
+	return nil
+}
+
+// Assign picks the member for a group. With no member available, the decision has no member
+// and the caller falls back. A rotation decision only counts once it is confirmed.
+func (rr *RoundRobin) Assign(ctx context.Context, group types.FileGroup) (types.RoundRobinDecision, error) {
+	if err := rr.load(ctx); err != nil {
+		return types.RoundRobinDecision{}, err
+	}
+	branch := rr.branch(group)
+	decision := types.RoundRobinDecision{GroupID: group.ID, Branch: branch, Skipped: make(map[string]string)}
+
+	// A group whose PR is still open keeps its member
+	for _, m := range rr.cfg.Members {
+		if s, ok := rr.state.Members[m.Name]; ok && slices.Contains(s.Open, branch) {
+			decision.Member = m.Name
+			decision.Reason = "pr_open"
+			return decision, nil
+		}
+	}
+
+	var best *config.RoundRobinMember
+	bestLoad := 0.0
+	for i, m := range rr.cfg.Members {
+		s := rr.member(m.Name)
+		switch {
+		case rr.unavailable[m.Name]:
+			decision.Skipped[m.Name] = "unavailable"
+			continue
+		case rr.cfg.MaxOpenPRs > 0 && len(s.Open) >= rr.cfg.MaxOpenPRs:
+			decision.Skipped[m.Name] = "open_pr_cap"
+			continue
+		}
+		weight := m.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		load := float64(s.Assigned) / float64(weight)
+		if best == nil || load < bestLoad {
+			best, bestLoad = &rr.cfg.Members[i], load
+		}
+	}
+	if best == nil {
+		decision.Reason = "no_member_available"
+		return decision, nil
+	}
+
+	decision.Member = best.Name
+	decision.Reason = "rotation"
+	return decision, nil
+}
+
+// Confirm records that the PR of a branch was opened for a member, so that it counts towards
+// the member's assignments and open PRs.
+func (rr *RoundRobin) Confirm(branch, member string) {
+	s := rr.member(member)
+	if slices.Contains(s.Open, branch) {
+		return
+	}
+	s.Assigned++
+	s.Open = append(s.Open, branch)
+}
+
+// Pool returns the members available for assignment.
+func (rr *RoundRobin) Pool() []string {
+	var pool []string
+	for _, m := range rr.cfg.Members {
+		if !rr.unavailable[m.Name] {
+			pool = append(pool, m.Name)
+		}
+	}
+	return pool
+}
+
+func (rr *RoundRobin) member(name string) *memberState {
+	if rr.state.Members == nil {
+		rr.state.Members = make(map[string]*memberState)
+	}
+	s, ok := rr.state.Members[name]
+	if !ok {
+		s = &memberState{}
+		rr.state.Members[name] = s
+	}
+	return s
+}
+
+// Save writes the state file, if any assignment was made this run.
+func (rr *RoundRobin) Save() error {
+	if !rr.loaded {
+		return nil
+	}
+	content, err := json.MarshalIndent(rr.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(rr.cfg.StateFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write round robin state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package assign
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func branchOf(group types.FileGroup) string {
+	return "recert/" + group.ID
+}
+
+// assignOpened assigns a group and confirms the decision, as the engine does once the PR is open.
+func assignOpened(t *testing.T, rr *RoundRobin, id string) types.RoundRobinDecision {
+	decision, err := rr.Assign(context.Background(), types.FileGroup{ID: id})
+	require.NoError(t, err)
+	if decision.Reason == "rotation" {
+		rr.Confirm(decision.Branch, decision.Member)
+	}
+	return decision
+}
+
+func TestRoundRobin_Assign(t *testing.T) {
+	cfg := config.RoundRobinConfig{
+		Members:     []config.RoundRobinMember{{Name: "alice", Weight: 2}, {Name: "bob"}, {Name: "carol"}},
+		StateFile:   filepath.Join(t.TempDir(), "state.json"),
+		Unavailable: []string{"carol"},
+	}
+	rr := NewRoundRobin(cfg, branchOf, func(context.Context, string) (bool, error) { return true, nil }, zap.NewNop())
+	ctx := context.Background()
+
+	var members []string
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		decision := assignOpened(t, rr, id)
+		assert.Equal(t, "unavailable", decision.Skipped["carol"])
+		members = append(members, decision.Member)
+	}
+	// alice takes two of every three assignments
+	assert.Equal(t, []string{"alice", "bob", "alice", "alice", "bob", "alice"}, members)
+
+	// A group whose PR is open keeps its member
+	decision, err := rr.Assign(ctx, types.FileGroup{ID: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", decision.Member)
+	assert.Equal(t, "pr_open", decision.Reason)
+
+	// A decision whose PR was not created doesn't count
+	decision, err = rr.Assign(ctx, types.FileGroup{ID: "g"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", decision.Member)
+	assert.Equal(t, "recert/g", decision.Branch)
+	decision, err = rr.Assign(ctx, types.FileGroup{ID: "h"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", decision.Member)
+	assert.Equal(t, "rotation", decision.Reason)
+}
+
+func TestRoundRobin_MaxOpenPRs(t *testing.T) {
+	cfg := config.RoundRobinConfig{
+		Members:    []config.RoundRobinMember{{Name: "alice"}, {Name: "bob"}},
+		StateFile:  filepath.Join(t.TempDir(), "state.json"),
+		MaxOpenPRs: 1,
+	}
+	rr := NewRoundRobin(cfg, branchOf, func(context.Context, string) (bool, error) { return true, nil }, zap.NewNop())
+	ctx := context.Background()
+
+	for _, want := range []string{"alice", "bob"} {
+		assert.Equal(t, want, assignOpened(t, rr, want).Member)
+	}
+	decision, err := rr.Assign(ctx, types.FileGroup{ID: "c"})
+	require.NoError(t, err)
+	assert.Empty(t, decision.Member)
+	assert.Equal(t, "no_member_available", decision.Reason)
+	assert.Equal(t, map[string]string{"alice": "open_pr_cap", "bob": "open_pr_cap"}, decision.Skipped)
+}
+
+func TestRoundRobin_StatePersisted(t *testing.T) {
+	cfg := config.RoundRobinConfig{
+		Members:   []config.RoundRobinMember{{Name: "alice"}, {Name: "bob"}},
+		StateFile: filepath.Join(t.TempDir(), "state.json"),
+	}
+	open := map[string]bool{}
+	isOpen := func(_ context.Context, branch string) (bool, error) { return open[branch], nil }
+
+	rr := NewRoundRobin(cfg, branchOf, isOpen, zap.NewNop())
+	assert.Equal(t, "alice", assignOpened(t, rr, "a").Member)
+	require.NoError(t, rr.Save())
+
+	// The rotation continues with bob, and the closed PR of group a no longer counts
+	rr = NewRoundRobin(cfg, branchOf, isOpen, zap.NewNop())
+	decision := assignOpened(t, rr, "a")
+	assert.Equal(t, "bob", decision.Member)
+	assert.Equal(t, "rotation", decision.Reason)
+	require.NoError(t, rr.Save())
+
+	content, err := os.ReadFile(cfg.StateFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"members": {"alice": {"assigned": 1}, "bob": {"assigned": 1, "open": ["recert/a"]}}}`, string(content))
+}
+
+func TestResolver_RoundRobin(t *testing.T) {
+	cfg := config.AssignmentConfig{
+		Strategy:          "round_robin",
+		FallbackAssignees: []string{"team"},
+		RoundRobin: config.RoundRobinConfig{
+			Members:     []config.RoundRobinMember{{Name: "alice"}},
+			StateFile:   filepath.Join(t.TempDir(), "state.json"),
+			Unavailable: []string{"alice"},
+		},
+	}
+	resolver := NewResolver(cfg, nil, zap.NewNop())
+	resolver.SetRoundRobin(NewRoundRobin(cfg.RoundRobin, branchOf, func(context.Context, string) (bool, error) { return false, nil }, zap.NewNop()))
+
+	result, err := resolver.Resolve(context.Background(), types.FileGroup{ID: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team"}, result.Assignees)
+	require.Len(t, result.RoundRobin, 1)
+	assert.Equal(t, "no_member_available", result.RoundRobin[0].Reason)
+}
+
+func TestResolver_RoundRobinComposite(t *testing.T) {
+	cfg := config.AssignmentConfig{
+		Strategy: "composite",
+		Rules: []config.AssignmentRule{
+			{Pattern: "terraform/**", Strategy: "round_robin"},
+		},
+		FallbackAssignees: []string{"team"},
+		RoundRobin: config.RoundRobinConfig{
+			Members:   []config.RoundRobinMember{{Name: "alice"}},
+			StateFile: filepath.Join(t.TempDir(), "state.json"),
+		},
+	}
+	// The branch is named after all files of the group, not only those of the rule
+	branch := func(group types.FileGroup) string {
+		return fmt.Sprintf("recert/%s-%d", group.ID, len(group.Files))
+	}
+	resolver := NewResolver(cfg, nil, zap.NewNop())
+	resolver.SetRoundRobin(NewRoundRobin(cfg.RoundRobin, branch, func(context.Context, string) (bool, error) { return false, nil }, zap.NewNop()))
+
+	result, err := resolver.Resolve(context.Background(), types.FileGroup{ID: "a", Files: []types.RecertCheckResult{
+		{File: types.FileInfo{RelPath: "terraform/main.tf"}},
+		{File: types.FileInfo{RelPath: "k8s/app.yaml"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "team"}, result.Assignees)
+	require.Len(t, result.RoundRobin, 1)
+	assert.Equal(t, "recert/a-2", result.RoundRobin[0].Branch)
+}
+
+func TestResolver_RoundRobinCompositeRules(t *testing.T) {
+	cfg := config.AssignmentConfig{
+		Strategy: "composite",
+		Rules: []config.AssignmentRule{
+			{Pattern: "terraform/**", Strategy: "round_robin"},
+			{Pattern: "k8s/**", Strategy: "round_robin"},
+		},
+		RoundRobin: config.RoundRobinConfig{
+			Members:   []config.RoundRobinMember{{Name: "alice"}, {Name: "bob"}},
+			StateFile: filepath.Join(t.TempDir(), "state.json"),
+		},
+	}
+	resolver := NewResolver(cfg, nil, zap.NewNop())
+	resolver.SetRoundRobin(NewRoundRobin(cfg.RoundRobin, branchOf, func(context.Context, string) (bool, error) { return false, nil }, zap.NewNop()))
+
+	result, err := resolver.Resolve(context.Background(), types.FileGroup{ID: "a", Files: []types.RecertCheckResult{
+		{File: types.FileInfo{RelPath: "terraform/main.tf"}},
+		{File: types.FileInfo{RelPath: "k8s/app.yaml"}},
+	}})
+	require.NoError(t, err)
+	// Every rule's decision is kept, so the engine confirms each of them
+	require.Len(t, result.RoundRobin, 2)
+	for _, decision := range result.RoundRobin {
+		assert.Equal(t, "rotation", decision.Reason)
+		assert.Equal(t, "recert/a", decision.Branch)
+	}
+}
+
+func TestResolver_PreviewRoundRobin(t *testing.T) {
+	cfg := config.AssignmentConfig{
+		Strategy: "round_robin",
+		RoundRobin: config.RoundRobinConfig{
+			Members:     []config.RoundRobinMember{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}},
+			StateFile:   filepath.Join(t.TempDir(), "state.json"),
+			Unavailable: []string{"bob"},
+		},
+	}
+	isOpen := func(context.Context, string) (bool, error) {
+		t.Fatal("a preview must not check PRs")
+		return false, nil
+	}
+	resolver := NewResolver(cfg, nil, zap.NewNop())
+	resolver.SetRoundRobin(NewRoundRobin(cfg.RoundRobin, branchOf, isOpen, zap.NewNop()))
+
+	result, err := resolver.Preview(context.Background(), types.FileGroup{ID: "forecast"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "carol"}, result.Assignees)
+	assert.Empty(t, result.RoundRobin)
+}
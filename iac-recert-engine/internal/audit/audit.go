@@ -25,7 +25,12 @@ const (
 	EventScanComplete   EventType = "scan_complete"
 	EventEnrichComplete EventType = "enrich_complete"
 	EventCheckComplete  EventType = "check_complete"
+	EventHistoryUnknown EventType = "history_unknown"
+	EventExempted       EventType = "exemption_applied"
+	EventExemptExpired  EventType = "exemption_expired"
 	EventGroupComplete  EventType = "group_complete"
+	EventAssigned       EventType = "assignment_resolved"
+	EventRoundRobin     EventType = "round_robin_assigned"
 	EventPRCreated      EventType = "pr_created"
 	EventPRError        EventType = "pr_error"
 	EventError          EventType = "error"
@@ -10,12 +10,18 @@ type Config struct {
 	Auth       AuthConfig       `yaml:"auth" mapstructure:"auth" validate:"required"`
 	Global     GlobalConfig     `yaml:"global" mapstructure:"global"`
 	Patterns   []Pattern        `yaml:"patterns" mapstructure:"patterns" validate:"required,dive"`
+	History    HistoryConfig    `yaml:"history" mapstructure:"history"`
+	Priorities []PriorityBucket `yaml:"priorities" mapstructure:"priorities" validate:"dive"`
+	Risk       RiskConfig       `yaml:"risk" mapstructure:"risk"`
 	PRStrategy PRStrategyConfig `yaml:"pr_strategy" mapstructure:"pr_strategy"`
 	Assignment AssignmentConfig `yaml:"assignment" mapstructure:"assignment"`
 	Plugins    PluginConfigs    `yaml:"plugins" mapstructure:"plugins"`
 	Schedule   ScheduleConfig   `yaml:"schedule" mapstructure:"schedule"`
 	PRTemplate PRTemplateConfig `yaml:"pr_template" mapstructure:"pr_template"`
 	Audit      AuditConfig      `yaml:"audit" mapstructure:"audit"`
+	Report     ReportConfig     `yaml:"report" mapstructure:"report"`
+	Exemptions ExemptionsConfig `yaml:"exemptions" mapstructure:"exemptions"`
+	Forecast   ForecastConfig   `yaml:"forecast" mapstructure:"forecast"`
 }
 
 type RepositoryConfig struct {
@@ -33,36 +39,137 @@ type GlobalConfig struct {
 	VerboseLogging    bool   `yaml:"verbose_logging" mapstructure:"verbose_logging"`
 	MaxConcurrentPRs  int    `yaml:"max_concurrent_prs" mapstructure:"max_concurrent_prs" validate:"min=1"`
 	DefaultBaseBranch string `yaml:"default_base_branch" mapstructure:"default_base_branch"`
+	// Also read .iceignore files from nested directories, not only from the repository root
+	NestedIceignore bool `yaml:"nested_iceignore" mapstructure:"nested_iceignore"`
 }
 
 type Pattern struct {
-	Name                string   `yaml:"name" mapstructure:"name" validate:"required"`
-	Description         string   `yaml:"description" mapstructure:"description"`
-	Paths               []string `yaml:"paths" mapstructure:"paths" validate:"required"`
-	Exclude             []string `yaml:"exclude" mapstructure:"exclude"`
-	RecertificationDays int      `yaml:"recertification_days" mapstructure:"recertification_days" validate:"required,min=1"`
-	Enabled             bool     `yaml:"enabled" mapstructure:"enabled"`
-	Decorator           string   `yaml:"decorator" mapstructure:"decorator"`
+	Name                string           `yaml:"name" mapstructure:"name" validate:"required"`
+	Description         string           `yaml:"description" mapstructure:"description"`
+	Paths               []string         `yaml:"paths" mapstructure:"paths" validate:"required"`
+	Exclude             []string         `yaml:"exclude" mapstructure:"exclude"`
+	RecertificationDays int              `yaml:"recertification_days" mapstructure:"recertification_days" validate:"required,min=1"`
+	Enabled             bool             `yaml:"enabled" mapstructure:"enabled"`
+	Decorator           string           `yaml:"decorator" mapstructure:"decorator"`
+	Mode                string           `yaml:"mode" mapstructure:"mode" validate:"omitempty,oneof=file terraform_module kubernetes_resource"`
+	UseDecoratorDate    bool             `yaml:"use_decorator_date" mapstructure:"use_decorator_date"`
+	DueBasis            string           `yaml:"due_basis" mapstructure:"due_basis" validate:"omitempty,oneof=latest modification certification earliest"`
+	Priorities          []PriorityBucket `yaml:"priorities" mapstructure:"priorities" validate:"dive"`    // Overrides the global buckets
+	RiskWeight          float64          `yaml:"risk_weight" mapstructure:"risk_weight" validate:"min=0"` // Risk multiplier, default 1
+}
+
+// PriorityBucket assigns a priority to results whose days-since/threshold ratio reaches MinRatio.
+// The bucket with the highest MinRatio reached applies; results below every bucket get no
+// priority, so a bucket with MinRatio 0 is the default.
+type PriorityBucket struct {
+	Name     string  `yaml:"name" mapstructure:"name" validate:"required"`
+	MinRatio float64 `yaml:"min_ratio" mapstructure:"min_ratio" validate:"min=0"`
+	SLADays  int     `yaml:"sla_days" mapstructure:"sla_days" validate:"min=0"` // Days after the due date to complete the review, 0 for none
+	Label    string  `yaml:"label" mapstructure:"label"`                        // Added to PRs containing results of this priority
+}
+
+// RiskConfig configures the risk score of results. The score is the overdue ratio multiplied by
+// the pattern weight, the environment weight and one factor per signal, so with every weight
+// left at its default the score equals the overdue ratio. Priority buckets apply to the score.
+type RiskConfig struct {
+	Enabled      bool              `yaml:"enabled" mapstructure:"enabled"`
+	Environments []RiskEnvironment `yaml:"environments" mapstructure:"environments" validate:"dive"`
+	Size         RiskSignal        `yaml:"size" mapstructure:"size"`       // File size in bytes
+	Churn        RiskSignal        `yaml:"churn" mapstructure:"churn"`     // Commits in the last 90 days
+	Authors      RiskSignal        `yaml:"authors" mapstructure:"authors"` // Distinct authors in the last year
+	// Risk plugin scoring results instead of the built-in model
+	PluginName string `yaml:"plugin_name" mapstructure:"plugin_name"`
+}
+
+// RiskEnvironment weights files whose path matches one of Paths. The first match applies.
+type RiskEnvironment struct {
+	Name   string   `yaml:"name" mapstructure:"name" validate:"required"`
+	Paths  []string `yaml:"paths" mapstructure:"paths" validate:"required"`
+	Weight float64  `yaml:"weight" mapstructure:"weight" validate:"gt=0"`
+}
+
+// RiskSignal raises the score by up to Weight (0.5 = +50%) as the signal approaches Cap.
+type RiskSignal struct {
+	Weight float64 `yaml:"weight" mapstructure:"weight" validate:"min=0"`
+	Cap    float64 `yaml:"cap" mapstructure:"cap" validate:"min=0"`
+}
+
+type HistoryConfig struct {
+	Source                string            `yaml:"source" mapstructure:"source" validate:"omitempty,oneof=provider local"`
+	IgnoreCosmeticChanges bool              `yaml:"ignore_cosmetic_changes" mapstructure:"ignore_cosmetic_changes"`
+	MaxCommits            int               `yaml:"max_commits" mapstructure:"max_commits" validate:"min=0"`
+	ExcludeCommits        []CommitExclusion `yaml:"exclude_commits" mapstructure:"exclude_commits" validate:"dive"`
+	CertificationMessages []string          `yaml:"certification_messages" mapstructure:"certification_messages"`
+	FollowRenames         bool              `yaml:"follow_renames" mapstructure:"follow_renames"`
+	IgnorePureRenames     *bool             `yaml:"ignore_pure_renames" mapstructure:"ignore_pure_renames"` // Default: FollowRenames
+	UnknownPolicy         string            `yaml:"unknown_policy" mapstructure:"unknown_policy" validate:"omitempty,oneof=treat_as_due skip report_only"`
+}
+
+// CommitExclusion matches commits that should not count as modifications.
+// Fields are regular expressions; all non-empty fields must match.
+type CommitExclusion struct {
+	Author  string `yaml:"author" mapstructure:"author"`
+	Email   string `yaml:"email" mapstructure:"email"`
+	Message string `yaml:"message" mapstructure:"message"`
 }
 
 type PRStrategyConfig struct {
-	Type          string `yaml:"type" mapstructure:"type" validate:"required,oneof=per_file per_pattern per_committer single_pr plugin"`
-	MaxFilesPerPR int    `yaml:"max_files_per_pr" mapstructure:"max_files_per_pr"`
-	PluginName    string `yaml:"plugin_name" mapstructure:"plugin_name"` // For plugin strategy
+	Type          string `yaml:"type" mapstructure:"type" validate:"required_without=GroupBy,omitempty,oneof=per_file per_pattern per_committer per_directory per_owner single_pr plugin"`
+	MaxFilesPerPR int    `yaml:"max_files_per_pr" mapstructure:"max_files_per_pr" validate:"min=0"`         // Split larger groups, 0 for no limit
+	PluginName    string `yaml:"plugin_name" mapstructure:"plugin_name" validate:"required_if=Type plugin"` // For plugin strategy
+	// For per_directory: the number of leading directories forming a group (0 for the whole
+	// parent directory), or a regular expression whose captures form the group
+	DirectoryDepth   int    `yaml:"directory_depth" mapstructure:"directory_depth" validate:"min=0"`
+	DirectoryPattern string `yaml:"directory_pattern" mapstructure:"directory_pattern"`
+	// Ordered group-by keys combined into one group per distinct set of values, instead of
+	// a preset Type: file, pattern, directory or directory:<depth>, committer, owner
+	GroupBy []string `yaml:"group_by" mapstructure:"group_by"`
+	// Split every group by priority, and only open PRs for results at or above MinPriority
+	SplitByPriority bool   `yaml:"split_by_priority" mapstructure:"split_by_priority"`
+	MinPriority     string `yaml:"min_priority" mapstructure:"min_priority"`
+	// Fold groups with fewer than MinFilesPerPR files into a catch-all group or their pattern group
+	MinFilesPerPR    int    `yaml:"min_files_per_pr" mapstructure:"min_files_per_pr" validate:"min=0"`
+	MergeSmallGroups string `yaml:"merge_small_groups" mapstructure:"merge_small_groups" validate:"omitempty,oneof=catch_all pattern"`
 }
 
 type AssignmentConfig struct {
-	Strategy          string           `yaml:"strategy" mapstructure:"strategy" validate:"required,oneof=static last_committer plugin composite"`
+	Strategy          string           `yaml:"strategy" mapstructure:"strategy" validate:"required,oneof=static last_committer plugin codeowners round_robin composite"`
 	PluginName        string           `yaml:"plugin_name" mapstructure:"plugin_name"`
 	Rules             []AssignmentRule `yaml:"rules" mapstructure:"rules"`
 	FallbackAssignees []string         `yaml:"fallback_assignees" mapstructure:"fallback_assignees"`
+	// Reviewers and teams (org/team) requested on every PR, in addition to the strategy's
+	Reviewers     []string `yaml:"reviewers" mapstructure:"reviewers"`
+	TeamReviewers []string `yaml:"team_reviewers" mapstructure:"team_reviewers"`
+	// YAML file mapping commit emails to usernames, for authors the provider can't resolve
+	UserDirectory string           `yaml:"user_directory" mapstructure:"user_directory"`
+	RoundRobin    RoundRobinConfig `yaml:"round_robin" mapstructure:"round_robin"`
+}
+
+// RoundRobinConfig configures the round_robin assignment strategy.
+type RoundRobinConfig struct {
+	Members []RoundRobinMember `yaml:"members" mapstructure:"members" validate:"dive"`
+	// File keeping the rotation between runs, required by round_robin. Relative paths are
+	// resolved against the working directory, so use a path that outlives the run.
+	StateFile string `yaml:"state_file" mapstructure:"state_file"`
+	// Maximum open recertification PRs per member, 0 for no limit
+	MaxOpenPRs  int      `yaml:"max_open_prs" mapstructure:"max_open_prs" validate:"min=0"`
+	Unavailable []string `yaml:"unavailable" mapstructure:"unavailable"`
+}
+
+type RoundRobinMember struct {
+	Name string `yaml:"name" mapstructure:"name" validate:"required"`
+	// Relative share of the assignments (default: 1)
+	Weight int `yaml:"weight" mapstructure:"weight" validate:"min=0"`
 }
 
 type AssignmentRule struct {
 	Pattern           string   `yaml:"pattern" mapstructure:"pattern" validate:"required"`
-	Strategy          string   `yaml:"strategy" mapstructure:"strategy" validate:"required,oneof=static last_committer plugin"`
+	Strategy          string   `yaml:"strategy" mapstructure:"strategy" validate:"required,oneof=static last_committer plugin codeowners round_robin"`
 	Plugin            string   `yaml:"plugin" mapstructure:"plugin"`
 	FallbackAssignees []string `yaml:"fallback_assignees" mapstructure:"fallback_assignees"`
+	// Reviewers and teams requested on PRs with files matching the rule
+	Reviewers     []string `yaml:"reviewers" mapstructure:"reviewers"`
+	TeamReviewers []string `yaml:"team_reviewers" mapstructure:"team_reviewers"`
 }
 
 type PluginConfigs map[string]PluginConfig
@@ -74,6 +181,32 @@ type PluginConfig struct {
 	Config  map[string]string `yaml:"config" mapstructure:"config"`
 }
 
+// ReportConfig controls the Markdown run report. No report is written when Path is empty.
+type ReportConfig struct {
+	Path string `yaml:"path" mapstructure:"path"`
+}
+
+// ExemptionsConfig locates the exemption files. Both files are read when set, central
+// exemptions first; a file is governed by the matching exemption that expires last, with ties
+// going to the earlier one.
+type ExemptionsConfig struct {
+	File     string `yaml:"file" mapstructure:"file"`           // Central file on the machine running ICE
+	RepoFile string `yaml:"repo_file" mapstructure:"repo_file"` // File within the repository, optional there
+}
+
+// ForecastConfig turns a run into a forecast: results are evaluated as of AsOf (YYYY-MM-DD,
+// default today) and the report lists the files becoming due within Horizon (e.g. 90d or 12w).
+// No pull requests are opened in forecast mode.
+type ForecastConfig struct {
+	AsOf    string `yaml:"as_of" mapstructure:"as_of"`
+	Horizon string `yaml:"horizon" mapstructure:"horizon"`
+}
+
+// Enabled reports whether the run is a forecast.
+func (f ForecastConfig) Enabled() bool {
+	return f.AsOf != "" || f.Horizon != ""
+}
+
 type ScheduleConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
 	Cron    string `yaml:"cron" mapstructure:"cron"`
@@ -84,6 +217,9 @@ type PRTemplateConfig struct {
 	IncludeFileList    bool   `yaml:"include_file_list" mapstructure:"include_file_list"`
 	IncludeChecklist   bool   `yaml:"include_checklist" mapstructure:"include_checklist"`
 	CustomInstructions string `yaml:"custom_instructions" mapstructure:"custom_instructions"`
+	// Branch name template with {group_id}, {pattern}, {strategy} and {hash} placeholders,
+	// recert/{group_id} by default. The result is sanitized into a valid git ref name.
+	Branch string `yaml:"branch" mapstructure:"branch"`
 }
 
 type AuditConfig struct {
@@ -161,6 +161,80 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "group_by without type",
+			config: Config{
+				Version: "1.0",
+				Repository: RepositoryConfig{
+					URL:      "https://github.com/org/repo",
+					Provider: "github",
+				},
+				Auth: AuthConfig{
+					Provider: "github",
+					TokenEnv: "GITHUB_TOKEN",
+				},
+				Global: GlobalConfig{
+					MaxConcurrentPRs: 1,
+				},
+				Patterns: []Pattern{
+					{
+						Name:                "terraform",
+						Paths:               []string{"**/*.tf"},
+						RecertificationDays: 90,
+					},
+				},
+				PRStrategy: PRStrategyConfig{
+					GroupBy: []string{"pattern", "directory:2"},
+				},
+				Assignment: AssignmentConfig{
+					Strategy: "static",
+				},
+				PRTemplate: PRTemplateConfig{
+					Title: "Recertification",
+				},
+				Audit: AuditConfig{
+					Storage: "file",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing pr strategy type and group_by",
+			config: Config{
+				Version: "1.0",
+				Repository: RepositoryConfig{
+					URL:      "https://github.com/org/repo",
+					Provider: "github",
+				},
+				Auth: AuthConfig{
+					Provider: "github",
+					TokenEnv: "GITHUB_TOKEN",
+				},
+				Global: GlobalConfig{
+					MaxConcurrentPRs: 1,
+				},
+				Patterns: []Pattern{
+					{
+						Name:                "terraform",
+						Paths:               []string{"**/*.tf"},
+						RecertificationDays: 90,
+					},
+				},
+				PRStrategy: PRStrategyConfig{
+					MaxFilesPerPR: 10,
+				},
+				Assignment: AssignmentConfig{
+					Strategy: "static",
+				},
+				PRTemplate: PRTemplateConfig{
+					Title: "Recertification",
+				},
+				Audit: AuditConfig{
+					Storage: "file",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -4,10 +4,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,6 +20,8 @@ import (
 	"github.com/baldator/iac-recert-engine/internal/plugin"
 	"github.com/baldator/iac-recert-engine/internal/pr"
 	"github.com/baldator/iac-recert-engine/internal/provider"
+	"github.com/baldator/iac-recert-engine/internal/report"
+	"github.com/baldator/iac-recert-engine/internal/risk"
 	"github.com/baldator/iac-recert-engine/internal/scan"
 	"github.com/baldator/iac-recert-engine/internal/strategy"
 	"github.com/baldator/iac-recert-engine/internal/types"
@@ -25,15 +30,19 @@ import (
 
 type Engine struct {
 	cfg      config.Config
+	runID    string
 	logger   *zap.Logger
 	auditor  *audit.Auditor
 	provider provider.GitProvider
 	scanner  *scan.Scanner
 	analyzer *scan.HistoryAnalyzer
 	checker  *scan.Checker
+	exempt   []types.Exemption // Central exemptions
 	strategy strategy.Strategy
 	resolver *assign.Resolver
 	prGen    *pr.Generator
+	rotation *assign.RoundRobin // Round robin state to persist after the run, if used
+	forecast *report.Forecast   // Window of a forecast run, nil for regular runs
 }
 
 func NewEngine(cfg config.Config, logger *zap.Logger) (*Engine, error) {
@@ -55,41 +64,146 @@ func NewEngine(cfg config.Config, logger *zap.Logger) (*Engine, error) {
 	}
 
 	// 3. Init Components
-	scanner := scan.NewScanner(cfg.Repository.URL, logger)
-	analyzer := scan.NewHistoryAnalyzer(prov, logger)
-	checker := scan.NewChecker(logger)
-
-	strat, err := strategy.NewStrategy(cfg.PRStrategy, logger)
+	scanner := scan.NewScanner(cfg.Repository.URL, cfg.History, cfg.Global.NestedIceignore, logger)
+	analyzer, err := scan.NewHistoryAnalyzer(cfg.History, prov, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init strategy: %w", err)
+		return nil, fmt.Errorf("failed to init history analyzer: %w", err)
 	}
-
 	pm, err := plugin.NewManager(cfg.Plugins, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init plugin manager: %w", err)
 	}
 
+	var scorer risk.Scorer
+	switch {
+	case cfg.Risk.Enabled && cfg.Risk.PluginName != "":
+		scorer, err = pm.GetRiskPlugin(cfg.Risk.PluginName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init risk scorer: %w", err)
+		}
+	case cfg.Risk.Enabled:
+		model, err := risk.NewModel(cfg.Risk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init risk model: %w", err)
+		}
+		scorer = model
+	}
+	checker := scan.NewChecker(cfg.Priorities, scorer, logger)
+
+	var forecast *report.Forecast
+	if cfg.Forecast.Enabled() {
+		asOf, until, err := forecastWindow(cfg.Forecast, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid forecast: %w", err)
+		}
+		checker.SetClock(func() time.Time { return asOf })
+		analyzer.SetClock(func() time.Time { return asOf })
+		forecast = &report.Forecast{AsOf: asOf, Until: until}
+	}
+
+	var exemptions []types.Exemption
+	if cfg.Exemptions.File != "" {
+		exemptions, err = scan.LoadExemptions(cfg.Exemptions.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exemptions: %w", err)
+		}
+	}
+
 	resolver := assign.NewResolver(cfg.Assignment, pm, logger)
+	var directory map[string]string
+	if cfg.Assignment.UserDirectory != "" {
+		directory, err = assign.LoadUserDirectory(cfg.Assignment.UserDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init assignment: %w", err)
+		}
+	}
+	resolver.SetIdentities(assign.NewIdentities(prov, directory, logger))
+
+	if cfg.PRStrategy.MinPriority != "" {
+		if err := checkPatternPriorities(cfg.Patterns, checker.Priorities()); err != nil {
+			return nil, fmt.Errorf("failed to init strategy: %w", err)
+		}
+	}
+	strat, err := strategy.NewStrategy(cfg.PRStrategy, checker.Priorities(), pm, resolver, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init strategy: %w", err)
+	}
 	prGen := pr.NewGenerator(cfg.PRTemplate)
 
+	var rotation *assign.RoundRobin
+	if resolver.UsesStrategy("round_robin") {
+		if groupsByOwner(cfg.PRStrategy) {
+			return nil, fmt.Errorf("failed to init assignment: round_robin can't be combined with grouping by owner")
+		}
+		if len(cfg.Assignment.RoundRobin.Members) == 0 {
+			return nil, fmt.Errorf("failed to init assignment: round_robin requires members")
+		}
+		if cfg.Assignment.RoundRobin.StateFile == "" {
+			return nil, fmt.Errorf("failed to init assignment: round_robin requires a state_file")
+		}
+		baseBranch := cfg.Global.DefaultBaseBranch
+		if baseBranch == "" {
+			baseBranch = "main"
+		}
+		rotation = assign.NewRoundRobin(cfg.Assignment.RoundRobin,
+			func(group types.FileGroup) string {
+				prCfg, err := prGen.Generate(group)
+				if err != nil {
+					return group.ID
+				}
+				return prCfg.Branch
+			},
+			func(ctx context.Context, branch string) (bool, error) {
+				return prov.PullRequestExists(ctx, branch, baseBranch)
+			}, logger)
+		resolver.SetRoundRobin(rotation)
+	}
+
 	return &Engine{
 		cfg:      cfg,
+		runID:    runID,
 		logger:   logger,
 		auditor:  auditor,
 		provider: prov,
 		scanner:  scanner,
 		analyzer: analyzer,
 		checker:  checker,
+		exempt:   exemptions,
 		strategy: strat,
 		resolver: resolver,
 		prGen:    prGen,
+		rotation: rotation,
+		forecast: forecast,
 	}, nil
 }
 
+// checkPatternPriorities rejects pattern-specific buckets named after no global bucket: min_priority
+// ranks priorities by the global buckets, so results in such a bucket couldn't be compared with it.
+func checkPatternPriorities(patterns []config.Pattern, global []config.PriorityBucket) error {
+	for _, p := range patterns {
+		if !p.Enabled {
+			continue
+		}
+		for _, bucket := range p.Priorities {
+			if !slices.ContainsFunc(global, func(b config.PriorityBucket) bool { return b.Name == bucket.Name }) {
+				return fmt.Errorf("pattern %s: priority %q is not a global priority bucket, which min_priority requires", p.Name, bucket.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// groupsByOwner reports whether the PR strategy resolves the assignment of each file to group
+// the files, which the round_robin rotation can't do.
+func groupsByOwner(cfg config.PRStrategyConfig) bool {
+	return cfg.Type == "per_owner" && len(cfg.GroupBy) == 0 || slices.Contains(cfg.GroupBy, "owner")
+}
+
 func (e *Engine) Run(ctx context.Context) error {
 	e.auditor.LogEvent(ctx, audit.EventRunStart, "Starting recertification run", map[string]any{
 		"repository": e.cfg.Repository.URL,
 		"dry_run":    e.cfg.Global.DryRun,
+		"forecast":   e.forecast != nil,
 	}, nil)
 
 	e.logger.Info("starting recertification run")
@@ -111,9 +225,13 @@ func (e *Engine) Run(ctx context.Context) error {
 			}
 		}
 	}()
+	scanStats := e.scanner.Stats()
 	e.auditor.LogEvent(ctx, audit.EventScanComplete, "File scan completed", map[string]any{
 		"files_scanned": len(files),
 		"scan_dir":      scanDir,
+		"ignored_files": scanStats.IgnoredFiles,
+		"ignored_dirs":  scanStats.IgnoredDirs,
+		"ignore_files":  scanStats.IgnoreFiles,
 	}, nil)
 	e.logger.Info("scanned files", zap.Int("count", len(files)), zap.String("scan_dir", scanDir))
 
@@ -131,6 +249,16 @@ func (e *Engine) Run(ctx context.Context) error {
 
 	// 3. Check
 	e.logger.Debug("starting recertification check phase")
+	exemptions, err := e.loadExemptions(scanDir)
+	if err != nil {
+		e.auditor.LogEvent(ctx, audit.EventError, "Loading exemptions failed", nil, err)
+		return fmt.Errorf("failed to load exemptions: %w", err)
+	}
+	e.checker.SetExemptions(exemptions)
+	if err := e.resolver.SetRepoRoot(scanDir); err != nil {
+		e.auditor.LogEvent(ctx, audit.EventError, "Loading CODEOWNERS failed", nil, err)
+		return fmt.Errorf("failed to load CODEOWNERS: %w", err)
+	}
 	results, err := e.checker.Check(enrichedFiles, e.cfg.Patterns, scanDir)
 	if err != nil {
 		e.auditor.LogEvent(ctx, audit.EventError, "Recertification check failed", nil, err)
@@ -141,13 +269,28 @@ func (e *Engine) Run(ctx context.Context) error {
 	}, nil)
 	e.logger.Debug("recertification check completed", zap.Int("check_results", len(results)))
 
+	e.recordExemptions(ctx, results)
+	results, skippedUnknown := e.applyUnknownHistoryPolicy(ctx, results)
+
+	if e.forecast != nil {
+		return e.runForecast(ctx, results, scanDir, skippedUnknown)
+	}
+
 	// 4. Group
 	e.logger.Debug("starting grouping phase")
-	groups, err := e.strategy.Group(results)
+	groups, err := e.strategy.Group(ctx, results)
 	if err != nil {
 		e.auditor.LogEvent(ctx, audit.EventError, "Grouping failed", nil, err)
 		return fmt.Errorf("grouping failed: %w", err)
 	}
+	if err := e.prGen.CheckBranches(groups); err != nil {
+		e.auditor.LogEvent(ctx, audit.EventError, "Grouping failed", nil, err)
+		return fmt.Errorf("invalid branch template: %w", err)
+	}
+	// Riskiest groups first, so their PRs are opened first
+	if e.cfg.Risk.Enabled {
+		strategy.OrderByRisk(groups)
+	}
 	e.auditor.LogEvent(ctx, audit.EventGroupComplete, "Grouping completed", map[string]any{
 		"groups_created": len(groups),
 	}, nil)
@@ -169,22 +312,267 @@ func (e *Engine) Run(ctx context.Context) error {
 		}
 	}
 
+	if e.rotation != nil && !e.cfg.Global.DryRun {
+		if err := e.rotation.Save(); err != nil {
+			e.auditor.LogEvent(ctx, audit.EventError, "Failed to save round robin state", nil, err)
+			e.logger.Warn("failed to save round robin state", zap.Error(err))
+		}
+	}
+
 	e.auditor.LogEvent(ctx, audit.EventRunEnd, "Recertification run completed", map[string]any{
 		"groups_processed": processed,
 		"groups_failed":    failed,
 	}, nil)
 	e.logger.Info("run completed", zap.Int("groups_processed", processed), zap.Int("groups_failed", failed))
+
+	if e.cfg.Report.Path != "" {
+		e.writeReport(ctx, e.newReport(results, scanDir, skippedUnknown))
+	}
+	return nil
+}
+
+func (e *Engine) newReport(results []types.RecertCheckResult, scanDir string, skippedUnknown int) report.Report {
+	return report.Report{
+		RunID:          e.runID,
+		Repository:     e.cfg.Repository.URL,
+		GeneratedAt:    time.Now(),
+		RepoRoot:       scanDir,
+		Results:        results,
+		UnknownPolicy:  e.cfg.History.UnknownPolicy,
+		SkippedUnknown: skippedUnknown,
+	}
+}
+
+// writeReport writes the report to report.path. A failure is logged rather than failing the run,
+// since the PRs have already been opened.
+func (e *Engine) writeReport(ctx context.Context, rep report.Report) {
+	if err := report.Write(e.cfg.Report.Path, rep); err != nil {
+		e.auditor.LogEvent(ctx, audit.EventError, "Failed to write report", map[string]any{
+			"path": e.cfg.Report.Path,
+		}, err)
+		e.logger.Error("failed to write report", zap.String("path", e.cfg.Report.Path), zap.Error(err))
+	} else {
+		e.logger.Info("report written", zap.String("path", e.cfg.Report.Path))
+	}
+}
+
+// runForecast reports the files due as of the forecast date and those becoming due within the
+// horizon, with their owners. It opens no pull requests. Without report.path the report is
+// printed to stdout.
+func (e *Engine) runForecast(ctx context.Context, results []types.RecertCheckResult, scanDir string, skippedUnknown int) error {
+	forecast := *e.forecast
+	forecast.Upcoming = e.upcoming(ctx, results, forecast.Until)
+
+	rep := e.newReport(results, scanDir, skippedUnknown)
+	rep.Forecast = &forecast
+	if e.cfg.Report.Path != "" {
+		e.writeReport(ctx, rep)
+	} else {
+		fmt.Print(report.Render(rep))
+	}
+
+	due := 0
+	for _, res := range results {
+		if res.NeedsRecert {
+			due++
+		}
+	}
+	e.auditor.LogEvent(ctx, audit.EventRunEnd, "Forecast completed", map[string]any{
+		"as_of":    forecast.AsOf,
+		"until":    forecast.Until,
+		"due":      due,
+		"upcoming": len(forecast.Upcoming),
+	}, nil)
+	e.logger.Info("forecast completed",
+		zap.Time("as_of", forecast.AsOf),
+		zap.Time("until", forecast.Until),
+		zap.Int("due", due),
+		zap.Int("upcoming", len(forecast.Upcoming)))
 	return nil
 }
 
+// upcoming returns the results that are not due yet but become due by until, with the owners
+// the assignment config resolves for each file. Exempted files become due when their
+// exemption expires.
+func (e *Engine) upcoming(ctx context.Context, results []types.RecertCheckResult, until time.Time) []report.Upcoming {
+	var upcoming []report.Upcoming
+	for _, res := range results {
+		if res.NeedsRecert || res.HistoryUnknown {
+			continue
+		}
+		dueDate := res.NextDueDate
+		if res.Exempted && res.Exemption.ExpiresAt.After(dueDate) {
+			dueDate = res.Exemption.ExpiresAt
+		}
+		if dueDate.After(until) {
+			continue
+		}
+
+		group := types.FileGroup{ID: "forecast", Strategy: "forecast", Files: []types.RecertCheckResult{res}}
+		assignment, err := e.resolver.Preview(ctx, group)
+		if err != nil {
+			e.logger.Warn("failed to resolve owner for forecast", zap.String("file", res.File.Path), zap.Error(err))
+		}
+		upcoming = append(upcoming, report.Upcoming{Result: res, DueDate: dueDate, Owners: assignment.Assignees})
+	}
+	return upcoming
+}
+
+// forecastWindow returns the date results are evaluated against and the end of the horizon.
+// as_of is a date (default now) and the horizon a number of days or weeks, e.g. 90d or 12w.
+func forecastWindow(cfg config.ForecastConfig, now time.Time) (time.Time, time.Time, error) {
+	asOf := now
+	if cfg.AsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", cfg.AsOf)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid as_of %q, expected YYYY-MM-DD", cfg.AsOf)
+		}
+	}
+	if cfg.Horizon == "" {
+		return asOf, asOf, nil
+	}
+
+	days := 0
+	n, err := strconv.Atoi(cfg.Horizon[:len(cfg.Horizon)-1])
+	switch {
+	case err != nil || n < 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid horizon %q, expected e.g. 90d or 12w", cfg.Horizon)
+	case strings.HasSuffix(cfg.Horizon, "d"):
+		days = n
+	case strings.HasSuffix(cfg.Horizon, "w"):
+		days = n * 7
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid horizon %q, expected e.g. 90d or 12w", cfg.Horizon)
+	}
+	return asOf, asOf.AddDate(0, 0, days), nil
+}
+
+// loadExemptions returns the central exemptions followed by those of the repository's
+// exemptions file, if the config names one and the repository has it.
+func (e *Engine) loadExemptions(scanDir string) ([]types.Exemption, error) {
+	exemptions := append([]types.Exemption(nil), e.exempt...)
+	if e.cfg.Exemptions.RepoFile == "" {
+		return exemptions, nil
+	}
+
+	repoExemptions, err := scan.LoadExemptions(filepath.Join(scanDir, filepath.FromSlash(e.cfg.Exemptions.RepoFile)))
+	if errors.Is(err, fs.ErrNotExist) {
+		e.logger.Debug("repository has no exemptions file", zap.String("file", e.cfg.Exemptions.RepoFile))
+		return exemptions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range repoExemptions {
+		repoExemptions[i].Source = e.cfg.Exemptions.RepoFile
+	}
+	e.logger.Info("loaded repository exemptions", zap.String("file", e.cfg.Exemptions.RepoFile), zap.Int("count", len(repoExemptions)))
+	return append(exemptions, repoExemptions...), nil
+}
+
+// recordExemptions writes an audit event for every exemption applied and every expired
+// exemption still matching a file.
+func (e *Engine) recordExemptions(ctx context.Context, results []types.RecertCheckResult) {
+	for _, res := range results {
+		if res.Exemption == nil {
+			continue
+		}
+		details := map[string]any{
+			"file":        res.File.RepoPath(),
+			"resource":    res.File.Resource,
+			"pattern":     res.PatternName,
+			"reason":      res.Exemption.Reason,
+			"approved_by": res.Exemption.ApprovedBy,
+			"expires_at":  res.Exemption.ExpiresAt,
+			"source":      res.Exemption.Source,
+		}
+		if res.Exempted {
+			e.auditor.LogEvent(ctx, audit.EventExempted, "Exemption applied", details, nil)
+		} else {
+			e.auditor.LogEvent(ctx, audit.EventExemptExpired, "Exemption expired", details, nil)
+		}
+	}
+}
+
+// applyUnknownHistoryPolicy handles files whose modification date could not be resolved, so a
+// provider outage doesn't open a PR for every file. With treat_as_due (the default) they stay
+// due, report_only keeps them out of PRs but in the report, and skip drops them altogether.
+// It returns the remaining results and the number of files skipped.
+func (e *Engine) applyUnknownHistoryPolicy(ctx context.Context, results []types.RecertCheckResult) ([]types.RecertCheckResult, int) {
+	policy := e.cfg.History.UnknownPolicy
+	if policy == "" {
+		policy = "treat_as_due"
+	}
+
+	var kept []types.RecertCheckResult
+	var unknown []string
+	for _, res := range results {
+		if !res.HistoryUnknown {
+			kept = append(kept, res)
+			continue
+		}
+		relPath := res.File.RepoPath()
+		if res.File.Resource != "" {
+			relPath += "#" + res.File.Resource
+		}
+		unknown = append(unknown, relPath)
+
+		switch policy {
+		case "skip":
+			continue
+		case "report_only":
+			res.NeedsRecert = false
+		}
+		kept = append(kept, res)
+	}
+	if len(unknown) == 0 {
+		return kept, 0
+	}
+
+	e.auditor.LogEvent(ctx, audit.EventHistoryUnknown, "Files with unknown history", map[string]any{
+		"policy": policy,
+		"count":  len(unknown),
+		"files":  unknown,
+	}, nil)
+	e.logger.Warn("files with unknown history", zap.String("policy", policy), zap.Int("count", len(unknown)), zap.Strings("files", unknown))
+
+	if policy == "skip" {
+		return kept, len(unknown)
+	}
+	return kept, 0
+}
+
 func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDir string, patterns []config.Pattern) error {
 	e.logger.Debug("processing group", zap.String("group_id", group.ID), zap.String("strategy", group.Strategy), zap.Int("files", len(group.Files)))
 
-	// Resolve Assignment
-	e.logger.Debug("resolving assignment for group", zap.String("group_id", group.ID))
-	assignment, err := e.resolver.Resolve(ctx, group)
-	if err != nil {
-		return fmt.Errorf("assignment resolution failed: %w", err)
+	// Resolve Assignment, unless the strategy grouped the files by their resolved owners
+	assignment := types.AssignmentResult{Assignees: group.Assignees, Reviewers: group.Reviewers, TeamReviewers: group.TeamReviewers, Rules: group.AssignmentRules}
+	if len(group.Assignees) == 0 && len(group.Reviewers) == 0 && len(group.TeamReviewers) == 0 {
+		e.logger.Debug("resolving assignment for group", zap.String("group_id", group.ID))
+		var err error
+		assignment, err = e.resolver.Resolve(ctx, group)
+		if err != nil {
+			return fmt.Errorf("assignment resolution failed: %w", err)
+		}
+	}
+	if len(assignment.Rules) > 0 {
+		e.auditor.LogEvent(ctx, audit.EventAssigned, "Assignment rules applied", map[string]any{
+			"group_id":  group.ID,
+			"rules":     assignment.Rules,
+			"assignees": assignment.Assignees,
+			"reviewers": assignment.Reviewers,
+			"teams":     assignment.TeamReviewers,
+		}, nil)
+	}
+
+	for _, rr := range assignment.RoundRobin {
+		e.auditor.LogEvent(ctx, audit.EventRoundRobin, "Round robin assignment", map[string]any{
+			"group_id": group.ID,
+			"member":   rr.Member,
+			"reason":   rr.Reason,
+			"skipped":  rr.Skipped,
+		}, nil)
 	}
 
 	// Generate PR Config
@@ -197,6 +585,7 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 	// Apply assignment
 	prCfg.Assignees = assignment.Assignees
 	prCfg.Reviewers = assignment.Reviewers
+	prCfg.TeamReviewers = assignment.TeamReviewers
 	prCfg.BaseBranch = e.cfg.Global.DefaultBaseBranch
 	if prCfg.BaseBranch == "" {
 		prCfg.BaseBranch = "main" // Default
@@ -208,7 +597,8 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 		zap.String("branch", prCfg.Branch),
 		zap.String("base_branch", prCfg.BaseBranch),
 		zap.Strings("assignees", prCfg.Assignees),
-		zap.Strings("reviewers", prCfg.Reviewers))
+		zap.Strings("reviewers", prCfg.Reviewers),
+		zap.Strings("team_reviewers", prCfg.TeamReviewers))
 
 	if e.cfg.Global.DryRun {
 		e.logger.Info("dry run: would create PR",
@@ -216,51 +606,12 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 			zap.String("branch", prCfg.Branch),
 			zap.Strings("assignees", prCfg.Assignees),
 		)
+		// The rotation advances in memory only, so each previewed group gets the member it would
+		e.confirmRoundRobin(assignment)
 		return nil
 	}
 
-	// Generate changes with decorators
-	var changes []types.Change
-	for _, res := range group.Files {
-		// Find the pattern
-		var pattern *config.Pattern
-		for i := range patterns {
-			if patterns[i].Name == res.PatternName {
-				pattern = &patterns[i]
-				break
-			}
-		}
-		if pattern == nil || pattern.Decorator == "" {
-			continue
-		}
-
-		// Read file content
-		relPath, err := filepath.Rel(scanDir, res.File.Path)
-		if err != nil {
-			e.logger.Warn("failed to get relative path for change", zap.String("file", res.File.Path), zap.Error(err))
-			continue
-		}
-		contentBytes, err := os.ReadFile(res.File.Path)
-		if err != nil {
-			e.logger.Warn("failed to read file for change", zap.String("file", res.File.Path), zap.Error(err))
-			continue
-		}
-		content := string(contentBytes)
-
-		// Remove existing decorator
-		existingDecorator := regexp.MustCompile(`^` + regexp.QuoteMeta(pattern.Decorator) + `.*\n?`)
-		content = existingDecorator.ReplaceAllString(content, "")
-
-		// Apply new decorator
-		decorator := strings.ReplaceAll(pattern.Decorator, "{timestamp}", time.Now().Format(time.RFC3339))
-		content = decorator + content
-
-		changes = append(changes, types.Change{
-			Path:    relPath,
-			Content: content,
-		})
-		e.logger.Debug("prepared change for file", zap.String("file", relPath), zap.String("decorator", pattern.Decorator))
-	}
+	changes := e.decorate(group, scanDir, patterns, time.Now())
 
 	// Check if branch already exists
 	e.logger.Debug("checking if branch already exists", zap.String("branch", prCfg.Branch))
@@ -282,7 +633,7 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 
 	// Create Commit
 	e.logger.Debug("creating commit", zap.String("branch", prCfg.Branch), zap.Int("changes", len(changes)))
-	_, err = e.provider.CreateCommit(ctx, prCfg.Branch, "Trigger recertification", changes)
+	_, err = e.provider.CreateCommit(ctx, prCfg.Branch, scan.RecertCommitMessage, changes)
 	if err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
@@ -310,6 +661,7 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 		}, err)
 		return fmt.Errorf("failed to create PR: %w", err)
 	}
+	e.confirmRoundRobin(assignment)
 
 	e.auditor.LogEvent(ctx, audit.EventPRCreated, "PR created successfully", map[string]any{
 		"group_id": group.ID,
@@ -321,6 +673,85 @@ func (e *Engine) processGroup(ctx context.Context, group types.FileGroup, scanDi
 	return nil
 }
 
+// confirmRoundRobin counts the rotation decisions of an assignment towards their members. The
+// state is only saved after a run that isn't a dry run.
+func (e *Engine) confirmRoundRobin(assignment types.AssignmentResult) {
+	if e.rotation == nil {
+		return
+	}
+	for _, rr := range assignment.RoundRobin {
+		if rr.Reason == "rotation" {
+			e.rotation.Confirm(rr.Branch, rr.Member)
+		}
+	}
+}
+
+// decorate renders the decorators of the group's files. Multi-file units (e.g. Terraform
+// modules) decorate every member file, and Kubernetes resources are decorated within their own
+// document, so that the recertification commit falls in each resource's line range. Resources
+// of one manifest share a single change.
+func (e *Engine) decorate(group types.FileGroup, scanDir string, patterns []config.Pattern, now time.Time) []types.Change {
+	var changes []types.Change
+	index := make(map[string]int) // Target file -> its change
+	for _, res := range group.Files {
+		// Find the pattern
+		var pattern *config.Pattern
+		for i := range patterns {
+			if patterns[i].Name == res.PatternName {
+				pattern = &patterns[i]
+				break
+			}
+		}
+		if pattern == nil || pattern.Decorator == "" {
+			continue
+		}
+		decorator := strings.ReplaceAll(pattern.Decorator, "{timestamp}", now.Format(time.RFC3339))
+
+		targets := []string{res.File.Path}
+		if len(res.File.Members) > 0 {
+			targets = res.File.Members
+		}
+
+		for _, target := range targets {
+			i, ok := index[target]
+			if ok && res.File.Resource == "" {
+				continue
+			}
+			if !ok {
+				// Read file content
+				relPath, err := filepath.Rel(scanDir, target)
+				if err != nil {
+					e.logger.Warn("failed to get relative path for change", zap.String("file", target), zap.Error(err))
+					continue
+				}
+				contentBytes, err := os.ReadFile(target)
+				if err != nil {
+					e.logger.Warn("failed to read file for change", zap.String("file", target), zap.Error(err))
+					continue
+				}
+				i = len(changes)
+				index[target] = i
+				changes = append(changes, types.Change{Path: relPath, Content: string(contentBytes)})
+			}
+
+			change := &changes[i]
+			if res.File.Resource != "" {
+				content, ok := scan.DecorateResource(pattern.Decorator, decorator, change.Content, res.File.Resource)
+				if !ok {
+					e.logger.Warn("failed to find resource to decorate", zap.String("file", change.Path), zap.String("resource", res.File.Resource))
+					continue
+				}
+				change.Content = content
+			} else {
+				// Replace the existing decorator
+				change.Content = decorator + scan.StripDecorator(pattern.Decorator, change.Content)
+			}
+			e.logger.Debug("prepared change for file", zap.String("file", change.Path), zap.String("resource", res.File.Resource), zap.String("decorator", pattern.Decorator))
+		}
+	}
+	return changes
+}
+
 // generateRunID generates a unique run ID
 func generateRunID() (string, error) {
 	bytes := make([]byte, 8)
@@ -6,8 +6,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/baldator/iac-recert-engine/internal/assign"
+	"github.com/baldator/iac-recert-engine/internal/audit"
 	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/pr"
+	"github.com/baldator/iac-recert-engine/internal/scan"
+	"github.com/baldator/iac-recert-engine/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -55,6 +61,50 @@ func TestNewEngine(t *testing.T) {
 		assert.Equal(t, cfg, engine.cfg)
 		assert.Equal(t, logger, engine.logger)
 	})
+
+	t.Run("round robin without state file", func(t *testing.T) {
+		cfg := config.Config{
+			Repository: config.RepositoryConfig{URL: "https://github.com/test/repo", Provider: "github"},
+			Auth:       config.AuthConfig{Provider: "github", TokenEnv: "GITHUB_TOKEN"},
+			PRStrategy: config.PRStrategyConfig{Type: "per_pattern"},
+			Assignment: config.AssignmentConfig{
+				Strategy:   "round_robin",
+				RoundRobin: config.RoundRobinConfig{Members: []config.RoundRobinMember{{Name: "alice"}}},
+			},
+		}
+
+		_, err := NewEngine(cfg, logger)
+		assert.ErrorContains(t, err, "round_robin requires a state_file")
+
+		cfg.Assignment.RoundRobin.StateFile = filepath.Join(t.TempDir(), "state.json")
+		_, err = NewEngine(cfg, logger)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pattern priority unknown to min_priority", func(t *testing.T) {
+		cfg := config.Config{
+			Repository: config.RepositoryConfig{URL: "https://github.com/test/repo", Provider: "github"},
+			Auth:       config.AuthConfig{Provider: "github", TokenEnv: "GITHUB_TOKEN"},
+			Patterns: []config.Pattern{
+				{
+					Name:                "prod",
+					Paths:               []string{"prod/**/*.tf"},
+					RecertificationDays: 60,
+					Enabled:             true,
+					Priorities:          []config.PriorityBucket{{Name: "Urgent", MinRatio: 1.0}, {Name: "Low"}},
+				},
+			},
+			PRStrategy: config.PRStrategyConfig{Type: "per_pattern", MinPriority: "High"},
+			Assignment: config.AssignmentConfig{Strategy: "static", FallbackAssignees: []string{"user1"}},
+		}
+
+		_, err := NewEngine(cfg, logger)
+		assert.ErrorContains(t, err, `pattern prod: priority "Urgent" is not a global priority bucket`)
+
+		cfg.Patterns[0].Priorities[0].Name = "Critical"
+		_, err = NewEngine(cfg, logger)
+		assert.NoError(t, err)
+	})
 }
 
 func TestEngine_Run(t *testing.T) {
@@ -270,3 +320,232 @@ func TestEngine_Run(t *testing.T) {
 		_ = err // We don't assert on the error since it depends on the provider
 	})
 }
+
+func TestEngine_ApplyUnknownHistoryPolicy(t *testing.T) {
+	logger := zap.NewNop()
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/scan/main.tf", RelPath: "main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/vpc.tf", RelPath: "vpc.tf"}, NeedsRecert: true, HistoryUnknown: true},
+	}
+
+	tests := []struct {
+		policy      string
+		wantResults int
+		wantDue     int
+		wantSkipped int
+	}{
+		{policy: "", wantResults: 2, wantDue: 2},
+		{policy: "treat_as_due", wantResults: 2, wantDue: 2},
+		{policy: "report_only", wantResults: 2, wantDue: 1},
+		{policy: "skip", wantResults: 1, wantDue: 1, wantSkipped: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			e := &Engine{
+				cfg:     config.Config{History: config.HistoryConfig{UnknownPolicy: tt.policy}},
+				logger:  logger,
+				auditor: audit.NewAuditor(config.AuditConfig{}, logger, "test-run"),
+			}
+			got, skipped := e.applyUnknownHistoryPolicy(context.Background(), results)
+			assert.Len(t, got, tt.wantResults)
+			assert.Equal(t, tt.wantSkipped, skipped)
+
+			due := 0
+			for _, res := range got {
+				if res.NeedsRecert {
+					due++
+				}
+			}
+			assert.Equal(t, tt.wantDue, due)
+		})
+	}
+}
+
+func TestEngine_DryRunAdvancesRoundRobin(t *testing.T) {
+	logger := zap.NewNop()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	cfg := config.Config{
+		Global: config.GlobalConfig{DryRun: true},
+		Assignment: config.AssignmentConfig{
+			Strategy: "round_robin",
+			RoundRobin: config.RoundRobinConfig{
+				Members:   []config.RoundRobinMember{{Name: "alice"}, {Name: "bob"}},
+				StateFile: stateFile,
+			},
+		},
+	}
+	rotation := assign.NewRoundRobin(cfg.Assignment.RoundRobin,
+		func(group types.FileGroup) string { return "recert/" + group.ID },
+		func(context.Context, string) (bool, error) { return false, nil }, logger)
+	resolver := assign.NewResolver(cfg.Assignment, nil, logger)
+	resolver.SetRoundRobin(rotation)
+	e := &Engine{
+		cfg:      cfg,
+		logger:   logger,
+		auditor:  audit.NewAuditor(config.AuditConfig{}, logger, "test-run"),
+		resolver: resolver,
+		prGen:    pr.NewGenerator(cfg.PRTemplate),
+		rotation: rotation,
+	}
+
+	require.NoError(t, e.processGroup(context.Background(), types.FileGroup{ID: "a"}, t.TempDir(), nil))
+	decision, err := rotation.Assign(context.Background(), types.FileGroup{ID: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", decision.Member, "the preview of the first group counts for the next one")
+	assert.NoFileExists(t, stateFile)
+}
+
+func TestEngine_LoadExemptions(t *testing.T) {
+	scanDir := t.TempDir()
+	central := []types.Exemption{{Paths: []string{"legacy/**"}, Reason: "decommission", ApprovedBy: "jane"}}
+	e := &Engine{
+		cfg:    config.Config{Exemptions: config.ExemptionsConfig{RepoFile: ".ice-exemptions.yaml"}},
+		logger: zap.NewNop(),
+		exempt: central,
+	}
+
+	// The repository file is optional
+	got, err := e.loadExemptions(scanDir)
+	require.NoError(t, err)
+	assert.Equal(t, central, got)
+
+	content := "exemptions:\n  - paths: [\"frozen/**\"]\n    reason: \"migration\"\n    approved_by: \"joe\"\n    expires_at: 2025-01-01\n"
+	require.NoError(t, os.WriteFile(filepath.Join(scanDir, ".ice-exemptions.yaml"), []byte(content), 0644))
+	got, err = e.loadExemptions(scanDir)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "decommission", got[0].Reason)
+	assert.Equal(t, "migration", got[1].Reason)
+	assert.Equal(t, ".ice-exemptions.yaml", got[1].Source)
+}
+
+func TestForecastWindow(t *testing.T) {
+	now := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		cfg       config.ForecastConfig
+		wantAsOf  time.Time
+		wantUntil time.Time
+		wantErr   bool
+	}{
+		{name: "horizon in days", cfg: config.ForecastConfig{Horizon: "90d"}, wantAsOf: now, wantUntil: now.AddDate(0, 0, 90)},
+		{name: "horizon in weeks", cfg: config.ForecastConfig{Horizon: "2w"}, wantAsOf: now, wantUntil: now.AddDate(0, 0, 14)},
+		{
+			name:      "as of date",
+			cfg:       config.ForecastConfig{AsOf: "2025-04-01", Horizon: "30d"},
+			wantAsOf:  time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			wantUntil: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "as of only", cfg: config.ForecastConfig{AsOf: "2025-04-01"}, wantAsOf: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), wantUntil: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid as of", cfg: config.ForecastConfig{AsOf: "next quarter"}, wantErr: true},
+		{name: "invalid horizon unit", cfg: config.ForecastConfig{Horizon: "3m"}, wantErr: true},
+		{name: "invalid horizon", cfg: config.ForecastConfig{Horizon: "d"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asOf, until, err := forecastWindow(tt.cfg, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAsOf, asOf)
+			assert.Equal(t, tt.wantUntil, until)
+		})
+	}
+}
+
+func TestEngine_Upcoming(t *testing.T) {
+	logger := zap.NewNop()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	e := &Engine{
+		logger:   logger,
+		resolver: assign.NewResolver(config.AssignmentConfig{Strategy: "static", FallbackAssignees: []string{"team"}}, nil, logger),
+	}
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "due.tf"}, NeedsRecert: true, NextDueDate: day(1)},
+		{File: types.FileInfo{Path: "soon.tf"}, NextDueDate: day(20)},
+		{File: types.FileInfo{Path: "later.tf"}, NextDueDate: day(31)},
+		{File: types.FileInfo{Path: "exempted.tf"}, NextDueDate: day(2), Exempted: true, Exemption: &types.Exemption{ExpiresAt: day(25)}},
+		{File: types.FileInfo{Path: "frozen.tf"}, NextDueDate: day(2), Exempted: true, Exemption: &types.Exemption{ExpiresAt: day(31)}},
+	}
+
+	got := e.upcoming(context.Background(), results, day(30))
+	require.Len(t, got, 2)
+	assert.Equal(t, "soon.tf", got[0].Result.File.Path)
+	assert.Equal(t, day(20), got[0].DueDate)
+	assert.Equal(t, []string{"team"}, got[0].Owners)
+	assert.Equal(t, "exempted.tf", got[1].Result.File.Path)
+	assert.Equal(t, day(25), got[1].DueDate)
+}
+
+func TestEngine_DecorateKubernetesResources(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+	repo := t.TempDir()
+	git := func(env []string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git(nil, "init")
+	git(nil, "config", "user.email", "test@example.com")
+	git(nil, "config", "user.name", "Test User")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "k8s"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "k8s", "app.yaml"), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`), 0644))
+	git(nil, "add", ".")
+	old := "2020-01-01T00:00:00Z"
+	git([]string{"GIT_AUTHOR_DATE=" + old, "GIT_COMMITTER_DATE=" + old}, "commit", "-m", "Add app")
+
+	patterns := []config.Pattern{{
+		Name:                "k8s",
+		Paths:               []string{"k8s/*.yaml"},
+		RecertificationDays: 90,
+		Enabled:             true,
+		Mode:                "kubernetes_resource",
+		Decorator:           "# Last Recertification: {timestamp}\n",
+	}}
+	check := func() ([]types.RecertCheckResult, string) {
+		history := config.HistoryConfig{Source: "local"}
+		files, scanDir, err := scan.NewScanner(repo, history, false, logger).Scan("", patterns)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(scanDir) })
+		analyzer, err := scan.NewHistoryAnalyzer(history, nil, logger)
+		require.NoError(t, err)
+		files, err = analyzer.Enrich(ctx, files, scanDir)
+		require.NoError(t, err)
+		results, err := scan.NewChecker(nil, nil, logger).Check(files, patterns, scanDir)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		return results, scanDir
+	}
+
+	results, scanDir := check()
+	for _, res := range results {
+		assert.True(t, res.NeedsRecert, res.File.Resource)
+	}
+
+	// The PR's commit decorates each resource within its own document, so once merged it
+	// certifies every resource of the manifest
+	e := &Engine{logger: logger}
+	changes := e.decorate(types.FileGroup{Files: results}, scanDir, patterns, time.Now())
+	require.Len(t, changes, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, changes[0].Path), []byte(changes[0].Content), 0644))
+	git(nil, "commit", "-am", scan.RecertCommitMessage)
+
+	results, _ = check()
+	for _, res := range results {
+		assert.False(t, res.NeedsRecert, res.File.Resource)
+	}
+}
@@ -16,6 +16,8 @@ type PluginType string
 const (
 	PluginTypeAssignment PluginType = "assignment"
 	PluginTypeFilter     PluginType = "filter"
+	PluginTypeGrouping   PluginType = "grouping"
+	PluginTypeRisk       PluginType = "risk"
 )
 
 type Plugin interface {
@@ -27,6 +29,19 @@ type AssignmentPlugin interface {
 	Resolve(files []types.FileInfo) (types.AssignmentResult, error)
 }
 
+// GroupingPlugin groups results into PRs. The returned groups carry the plugin's group name as
+// their ID; results left out of every group are not returned.
+type GroupingPlugin interface {
+	Plugin
+	Group(results []types.RecertCheckResult) ([]types.FileGroup, error)
+}
+
+// RiskPlugin scores results. It implements risk.Scorer, so it can replace the built-in model.
+type RiskPlugin interface {
+	Plugin
+	Score(result types.RecertCheckResult, pattern config.Pattern, relPath string) (float64, []types.RiskFactor)
+}
+
 // assignmentPluginWrapper wraps an api.AssignmentPlugin to implement the internal AssignmentPlugin interface
 type assignmentPluginWrapper struct {
 	apiPlugin api.AssignmentPlugin
@@ -40,15 +55,7 @@ func (w *assignmentPluginWrapper) Resolve(files []types.FileInfo) (types.Assignm
 	// Convert internal FileInfo to api.FileInfo
 	var apiFiles []api.FileInfo
 	for _, f := range files {
-		apiFiles = append(apiFiles, api.FileInfo{
-			Path:         f.Path,
-			Size:         f.Size,
-			LastModified: f.LastModified.Format("2006-01-02T15:04:05Z07:00"),
-			CommitHash:   f.CommitHash,
-			CommitAuthor: f.CommitAuthor,
-			CommitEmail:  f.CommitEmail,
-			CommitMsg:    f.CommitMsg,
-		})
+		apiFiles = append(apiFiles, toAPIFileInfo(f))
 	}
 
 	// Call the API plugin
@@ -66,6 +73,104 @@ func (w *assignmentPluginWrapper) Resolve(files []types.FileInfo) (types.Assignm
 	}, nil
 }
 
+// groupingPluginWrapper wraps an api.GroupingPlugin to implement the internal GroupingPlugin interface
+type groupingPluginWrapper struct {
+	apiPlugin api.GroupingPlugin
+}
+
+func (w *groupingPluginWrapper) Init(config map[string]string) error {
+	return w.apiPlugin.Init(config)
+}
+
+func (w *groupingPluginWrapper) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	// Results are matched back by path and resource, since Kubernetes resources share their file
+	byKey := make(map[string]types.RecertCheckResult, len(results))
+	var apiResults []api.RecertCheckResult
+	for _, res := range results {
+		byKey[res.File.Path+"#"+res.File.Resource] = res
+		apiResults = append(apiResults, api.RecertCheckResult{
+			File:        toAPIFileInfo(res.File),
+			PatternName: res.PatternName,
+			DaysSince:   res.DaysSince,
+			Threshold:   res.Threshold,
+			Priority:    res.Priority,
+			NextDueDate: res.NextDueDate.Format("2006-01-02T15:04:05Z07:00"),
+			RiskScore:   res.RiskScore,
+		})
+	}
+
+	apiGroups, err := w.apiPlugin.Group(apiResults)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []types.FileGroup
+	for _, g := range apiGroups {
+		if g.Name == "" {
+			return nil, fmt.Errorf("plugin returned a group without a name")
+		}
+		group := types.FileGroup{ID: g.Name}
+		for _, f := range g.Files {
+			res, ok := byKey[f.Path+"#"+f.Resource]
+			if !ok {
+				return nil, fmt.Errorf("plugin returned unknown file %s in group %s", f.Path, g.Name)
+			}
+			group.Files = append(group.Files, res)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// riskPluginWrapper wraps an api.RiskPlugin to implement the internal RiskPlugin interface
+type riskPluginWrapper struct {
+	apiPlugin api.RiskPlugin
+	logger    *zap.Logger
+}
+
+func (w *riskPluginWrapper) Init(config map[string]string) error {
+	return w.apiPlugin.Init(config)
+}
+
+// Score returns the plugin's score, or the overdue ratio when the plugin fails.
+func (w *riskPluginWrapper) Score(result types.RecertCheckResult, pattern config.Pattern, relPath string) (float64, []types.RiskFactor) {
+	file := toAPIFileInfo(result.File)
+	file.RelPath = relPath
+	score, err := w.apiPlugin.Score(api.RecertCheckResult{
+		File:        file,
+		PatternName: pattern.Name,
+		DaysSince:   result.DaysSince,
+		Threshold:   result.Threshold,
+		Priority:    result.Priority,
+		NextDueDate: result.NextDueDate.Format("2006-01-02T15:04:05Z07:00"),
+	})
+	if err != nil {
+		w.logger.Warn("risk plugin failed, using overdue ratio", zap.String("file", relPath), zap.Error(err))
+		ratio := float64(result.DaysSince) / float64(result.Threshold)
+		return ratio, []types.RiskFactor{{Name: "overdue", Detail: fmt.Sprintf("%d/%d days", result.DaysSince, result.Threshold), Factor: ratio}}
+	}
+
+	var factors []types.RiskFactor
+	for _, f := range score.Factors {
+		factors = append(factors, types.RiskFactor{Name: f.Name, Detail: f.Detail, Factor: f.Factor})
+	}
+	return score.Score, factors
+}
+
+func toAPIFileInfo(f types.FileInfo) api.FileInfo {
+	return api.FileInfo{
+		Path:         f.Path,
+		RelPath:      f.RelPath,
+		Size:         f.Size,
+		LastModified: f.LastModified.Format("2006-01-02T15:04:05Z07:00"),
+		CommitHash:   f.CommitHash,
+		CommitAuthor: f.CommitAuthor,
+		CommitEmail:  f.CommitEmail,
+		CommitMsg:    f.CommitMsg,
+		Resource:     f.Resource,
+	}
+}
+
 type Manager struct {
 	plugins map[string]Plugin
 	logger  *zap.Logger
@@ -93,11 +198,16 @@ func NewManager(configs config.PluginConfigs, logger *zap.Logger) (*Manager, err
 			apiPlugin := servicenow.NewServiceNowPlugin(logger)
 			plugin = &assignmentPluginWrapper{apiPlugin: apiPlugin}
 		case "csvlookup":
-			if cfg.Type != "assignment" {
-				return nil, fmt.Errorf("csvlookup plugin must be of type assignment")
+			switch PluginType(cfg.Type) {
+			case PluginTypeAssignment:
+				plugin = &assignmentPluginWrapper{apiPlugin: csvlookup.NewCSVLookupPlugin(logger)}
+			case PluginTypeGrouping:
+				plugin = &groupingPluginWrapper{apiPlugin: csvlookup.NewCSVLookupGroupingPlugin(logger)}
+			case PluginTypeRisk:
+				plugin = &riskPluginWrapper{apiPlugin: csvlookup.NewCSVLookupRiskPlugin(logger), logger: logger}
+			default:
+				return nil, fmt.Errorf("csvlookup plugin must be of type assignment, grouping or risk")
 			}
-			apiPlugin := csvlookup.NewCSVLookupPlugin(logger)
-			plugin = &assignmentPluginWrapper{apiPlugin: apiPlugin}
 		default:
 			return nil, fmt.Errorf("unknown plugin module: %s", cfg.Module)
 		}
@@ -123,3 +233,27 @@ func (m *Manager) GetAssignmentPlugin(name string) (AssignmentPlugin, error) {
 	}
 	return ap, nil
 }
+
+func (m *Manager) GetGroupingPlugin(name string) (GroupingPlugin, error) {
+	p, ok := m.plugins[name]
+	if !ok {
+		return nil, fmt.Errorf("plugin not found: %s", name)
+	}
+	gp, ok := p.(GroupingPlugin)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s is not a grouping plugin", name)
+	}
+	return gp, nil
+}
+
+func (m *Manager) GetRiskPlugin(name string) (RiskPlugin, error) {
+	p, ok := m.plugins[name]
+	if !ok {
+		return nil, fmt.Errorf("plugin not found: %s", name)
+	}
+	rp, ok := p.(RiskPlugin)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s is not a risk plugin", name)
+	}
+	return rp, nil
+}
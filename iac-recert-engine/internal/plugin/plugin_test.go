@@ -0,0 +1,38 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/baldator/iac-recert-engine/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeRiskPlugin doubles the overdue ratio, and fails for broken.tf.
+type fakeRiskPlugin struct{}
+
+func (fakeRiskPlugin) Init(map[string]string) error { return nil }
+
+func (fakeRiskPlugin) Score(result api.RecertCheckResult) (api.RiskScore, error) {
+	if result.File.RelPath == "broken.tf" {
+		return api.RiskScore{}, errors.New("lookup failed")
+	}
+	ratio := float64(result.DaysSince) / float64(result.Threshold)
+	return api.RiskScore{Score: ratio * 2, Factors: []api.RiskFactor{{Name: "overdue", Factor: ratio}, {Name: "app", Detail: result.PatternName, Factor: 2}}}, nil
+}
+
+func TestRiskPluginWrapper_Score(t *testing.T) {
+	w := &riskPluginWrapper{apiPlugin: fakeRiskPlugin{}, logger: zap.NewNop()}
+	result := types.RecertCheckResult{DaysSince: 90, Threshold: 60}
+
+	score, factors := w.Score(result, config.Pattern{Name: "prod"}, "prod/main.tf")
+	assert.InDelta(t, 3.0, score, 1e-9)
+	assert.Equal(t, []types.RiskFactor{{Name: "overdue", Factor: 1.5}, {Name: "app", Detail: "prod", Factor: 2}}, factors)
+
+	score, factors = w.Score(result, config.Pattern{Name: "prod"}, "broken.tf")
+	assert.InDelta(t, 1.5, score, 1e-9)
+	assert.Equal(t, []types.RiskFactor{{Name: "overdue", Detail: "90/60 days", Factor: 1.5}}, factors)
+}
@@ -1,10 +1,15 @@
 package pr
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/risk"
 	"github.com/baldator/iac-recert-engine/internal/types"
 )
 
@@ -22,25 +27,92 @@ func (g *Generator) Generate(group types.FileGroup) (types.PRConfig, error) {
 	// Simple replacement
 	title = strings.ReplaceAll(title, "{pattern_name}", getPatternName(group))
 	title = strings.ReplaceAll(title, "{file_count}", fmt.Sprintf("%d", len(group.Files)))
+	if group.Parts > 1 {
+		// Chunks of a split group would otherwise share a title
+		if strings.Contains(title, "{part}") || strings.Contains(title, "{parts}") {
+			title = strings.ReplaceAll(title, "{part}", fmt.Sprintf("%d", group.Part))
+			title = strings.ReplaceAll(title, "{parts}", fmt.Sprintf("%d", group.Parts))
+		} else {
+			title = fmt.Sprintf("%s (part %d of %d)", title, group.Part, group.Parts)
+		}
+	} else {
+		title = strings.ReplaceAll(title, "{part}", "1")
+		title = strings.ReplaceAll(title, "{parts}", "1")
+	}
 
 	// 2. Generate Description
 	var sb strings.Builder
 	sb.WriteString("## Recertification Required\n\n")
 	sb.WriteString("The following infrastructure files are due for recertification.\n\n")
+	if group.Parts > 1 {
+		sb.WriteString(fmt.Sprintf("This is part %d of %d: the files were split across %d pull requests to keep each review manageable.\n\n", group.Part, group.Parts, group.Parts))
+	}
 
 	if g.cfg.IncludeFileList {
 		sb.WriteString("### Files\n\n")
-		sb.WriteString("| Path | Last Modified | Author | Priority |\n")
-		sb.WriteString("|---|---|---|---|\n")
+		sb.WriteString("| Path | Last Modified | Last Certified | Author | Priority |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
 		for _, f := range group.Files {
-			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
-				f.File.Path,
-				f.File.LastModified.Format("2006-01-02"),
+			path := f.File.Path
+			if f.File.Resource != "" {
+				path = fmt.Sprintf("%s (%s, lines %d-%d)", f.File.Path, f.File.Resource, f.File.StartLine, f.File.EndLine)
+			}
+			lastModified := "unknown"
+			if !f.HistoryUnknown {
+				lastModified = f.File.LastModified.Format("2006-01-02")
+			}
+			lastCertified := "-"
+			if !f.LastCertified.IsZero() {
+				lastCertified = f.LastCertified.Format("2006-01-02")
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+				path,
+				lastModified,
+				lastCertified,
 				f.File.CommitAuthor,
 				f.Priority,
 			))
 		}
 		sb.WriteString("\n")
+
+		// Risk scores are only broken down when risk scoring is enabled
+		var scored []types.RecertCheckResult
+		for _, f := range group.Files {
+			if len(f.RiskBreakdown) > 0 {
+				scored = append(scored, f)
+			}
+		}
+		if len(scored) > 0 {
+			sb.WriteString("### Risk\n\n")
+			sb.WriteString("| Path | Score | Breakdown |\n")
+			sb.WriteString("|---|---|---|\n")
+			for _, f := range scored {
+				sb.WriteString(fmt.Sprintf("| %s | %.2f | %s |\n", f.File.Path, f.RiskScore, risk.FormatBreakdown(f.RiskBreakdown)))
+			}
+			sb.WriteString("\n")
+		}
+
+		// Multi-file units (e.g. Terraform modules) list their member files
+		var units []types.RecertCheckResult
+		for _, f := range group.Files {
+			if len(f.File.Members) > 0 {
+				units = append(units, f)
+			}
+		}
+		if len(units) > 0 {
+			sb.WriteString("### Module Files\n\n")
+			for _, f := range units {
+				sb.WriteString(fmt.Sprintf("**%s**\n\n", f.File.Path))
+				for _, m := range f.File.Members {
+					name, err := filepath.Rel(f.File.Path, m)
+					if err != nil {
+						name = m
+					}
+					sb.WriteString(fmt.Sprintf("- %s\n", filepath.ToSlash(name)))
+				}
+				sb.WriteString("\n")
+			}
+		}
 	}
 
 	if g.cfg.IncludeChecklist {
@@ -57,20 +129,130 @@ func (g *Generator) Generate(group types.FileGroup) (types.PRConfig, error) {
 	}
 
 	// 3. Build PRConfig
-	// Note: Branch name generation should probably happen here or in Engine.
-	// Spec says: branch recert/{pattern}/{file-hash} etc.
-	// Let's assume Engine handles branch naming or we do it here.
-	// Let's do it here based on group ID.
-	branchName := fmt.Sprintf("recert/%s", group.ID)
+	branchName := g.branchName(group)
+
+	// Label the PR with the priority buckets of its files
+	var labels []string
+	seen := make(map[string]bool)
+	for _, f := range group.Files {
+		if f.PriorityLabel != "" && !seen[f.PriorityLabel] {
+			seen[f.PriorityLabel] = true
+			labels = append(labels, f.PriorityLabel)
+		}
+	}
 
 	return types.PRConfig{
 		Title:       title,
 		Description: sb.String(),
 		Branch:      branchName,
+		Labels:      labels,
 		// BaseBranch, Assignees, Reviewers will be filled by Engine/Resolver
 	}, nil
 }
 
+// defaultBranchTemplate keeps one branch per group, under the group's patterns and named after
+// the hash of the group ID.
+const defaultBranchTemplate = "recert/{pattern}/{hash}"
+
+// branchName renders the branch template for a group. Group IDs are built from repo-relative
+// paths, pattern names and committers, so the same group gets the same branch on every run and
+// an open recertification PR is found again.
+func (g *Generator) branchName(group types.FileGroup) string {
+	tmpl := g.cfg.Branch
+	if tmpl == "" {
+		tmpl = defaultBranchTemplate
+	}
+	hash := sha256.Sum256([]byte(group.ID))
+	name := strings.NewReplacer(
+		"{group_id}", group.ID,
+		"{pattern}", branchPatternName(group),
+		"{strategy}", group.Strategy,
+		"{hash}", hex.EncodeToString(hash[:])[:12],
+	).Replace(tmpl)
+	return SanitizeBranchName(name)
+}
+
+// CheckBranches returns an error when two groups get the same branch, or the branch of one group
+// is a directory of another's, e.g. recert/envs and recert/envs/prod: git stores refs as files,
+// so the second branch can't be created. Group IDs containing paths can do this with a template
+// using {group_id}, which {hash} avoids.
+func (g *Generator) CheckBranches(groups []types.FileGroup) error {
+	owners := make(map[string]string, len(groups)) // Branch to group ID
+	for _, group := range groups {
+		branch := g.branchName(group)
+		if other, ok := owners[branch]; ok {
+			return fmt.Errorf("groups %s and %s both use branch %s", other, group.ID, branch)
+		}
+		owners[branch] = group.ID
+	}
+	for branch, id := range owners {
+		for i, r := range branch {
+			if r != '/' {
+				continue
+			}
+			if other, ok := owners[branch[:i]]; ok {
+				return fmt.Errorf("branch %s of group %s conflicts with branch %s of group %s; use {hash} in the branch template instead of {group_id}", branch, id, branch[:i], other)
+			}
+		}
+	}
+	return nil
+}
+
+// branchPatternName returns the patterns of a group's files in sorted order, joined by "+".
+// Unlike the first file's pattern it doesn't depend on the order of the files.
+func branchPatternName(group types.FileGroup) string {
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, f := range group.Files {
+		if !seen[f.PatternName] {
+			seen[f.PatternName] = true
+			patterns = append(patterns, f.PatternName)
+		}
+	}
+	if len(patterns) == 0 {
+		return "unknown"
+	}
+	sort.Strings(patterns)
+	return strings.Join(patterns, "+")
+}
+
+// SanitizeBranchName turns name into a branch name accepted by git check-ref-format: characters
+// git rejects (spaces, control characters and ~^:?*[\) become dashes, as do "..", "@{" and a
+// leading dot or trailing ".lock" of a component; trailing dots of a component and empty
+// components are dropped.
+func SanitizeBranchName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.ReplaceAll(name, "@{", "-")
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", "-")
+	}
+
+	var components []string
+	for _, c := range strings.Split(name, "/") {
+		// Trailing dots go first, so that a ".lock" they hide is caught
+		c = strings.TrimRight(c, ".")
+		if strings.HasPrefix(c, ".") {
+			c = "-" + strings.TrimLeft(c, ".")
+		}
+		if strings.HasSuffix(c, ".lock") {
+			c = strings.TrimSuffix(c, ".lock") + "-lock"
+		}
+		if c != "" {
+			components = append(components, c)
+		}
+	}
+	name = strings.Join(components, "/")
+	if name == "" || name == "@" {
+		return "recert"
+	}
+	return name
+}
+
 func getPatternName(group types.FileGroup) string {
 	// Try to extract from ID or use first file's pattern
 	if len(group.Files) > 0 {
@@ -0,0 +1,78 @@
+package pr
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Branch(t *testing.T) {
+	group := types.FileGroup{
+		ID:       "file-terraform/prod/main.tf",
+		Strategy: "per_file",
+		Files: []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "/tmp/iac-recert-scan-123/terraform/prod/main.tf", RelPath: "terraform/prod/main.tf"}, PatternName: "terraform-prod"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "default", want: "recert/terraform-prod/361a81c0eea3"},
+		{name: "group id", template: "recert/{group_id}", want: "recert/file-terraform/prod/main.tf"},
+		{name: "strategy", template: "ice/{strategy}/{group_id}", want: "ice/per_file/file-terraform/prod/main.tf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prCfg, err := NewGenerator(config.PRTemplateConfig{Title: "Recertify", Branch: tt.template}).Generate(group)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, prCfg.Branch)
+		})
+	}
+}
+
+func TestGenerator_CheckBranches(t *testing.T) {
+	groups := []types.FileGroup{
+		{ID: "dir-envs", Files: []types.RecertCheckResult{{PatternName: "terraform"}}},
+		{ID: "dir-envs-dev", Files: []types.RecertCheckResult{{PatternName: "terraform"}}},
+		{ID: "dir-envs/prod", Files: []types.RecertCheckResult{{PatternName: "terraform"}}},
+	}
+
+	err := NewGenerator(config.PRTemplateConfig{Branch: "recert/{group_id}"}).CheckBranches(groups)
+	assert.ErrorContains(t, err, "branch recert/dir-envs/prod of group dir-envs/prod conflicts with branch recert/dir-envs of group dir-envs")
+
+	err = NewGenerator(config.PRTemplateConfig{Branch: "recert/{pattern}"}).CheckBranches(groups)
+	assert.ErrorContains(t, err, "both use branch recert/terraform")
+
+	assert.NoError(t, NewGenerator(config.PRTemplateConfig{}).CheckBranches(groups))
+	assert.NoError(t, NewGenerator(config.PRTemplateConfig{Branch: "recert/{group_id}"}).CheckBranches(groups[:2]))
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := map[string]string{
+		"recert/author-Jane Doe":              "recert/author-Jane-Doe",
+		"recert/file-/tmp/scan/main.tf":       "recert/file-/tmp/scan/main.tf",
+		"recert//dir-root/":                   "recert/dir-root",
+		"recert/../x":                         "recert/-/x",
+		"recert/.hidden/config.lock":          "recert/-hidden/config-lock",
+		"recert/k8s/Deployment:prod?*[x]~^\\": "recert/k8s/Deployment-prod---x]---",
+		"recert/owner-a@{b}.":                 "recert/owner-a-b}",
+		"recert/foo.lock.":                    "recert/foo-lock",
+		"recert/foo.lock./main.tf..":          "recert/foo-lock/main.tf-",
+		"recert/pattern-terraform+k8s-part-1": "recert/pattern-terraform+k8s-part-1",
+		"@":                                   "recert",
+	}
+	for name, want := range tests {
+		got := SanitizeBranchName(name)
+		assert.Equal(t, want, got, name)
+		if _, err := exec.LookPath("git"); err == nil {
+			assert.NoError(t, exec.Command("git", "check-ref-format", "--branch", got).Run(), got)
+		}
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,6 +27,8 @@ type AzureDevOpsProvider struct {
 	token   string
 	logger  *zap.Logger
 	baseURL string
+	// identityURL is the organization's identity service API, which resolves reviewers to IDs
+	identityURL string
 }
 
 func NewAzureDevOpsProvider(ctx context.Context, repoCfg config.RepositoryConfig, authCfg config.AuthConfig, logger *zap.Logger) (GitProvider, error) {
@@ -57,17 +61,22 @@ func NewAzureDevOpsProvider(ctx context.Context, repoCfg config.RepositoryConfig
 	}
 
 	return &AzureDevOpsProvider{
-		client:  &http.Client{},
-		org:     org,
-		project: project,
-		repo:    repo,
-		token:   token,
-		logger:  logger,
-		baseURL: fmt.Sprintf("https://dev.azure.com/%s/%s/_apis", org, project),
+		client:      &http.Client{},
+		org:         org,
+		project:     project,
+		repo:        repo,
+		token:       token,
+		logger:      logger,
+		baseURL:     fmt.Sprintf("https://dev.azure.com/%s/%s/_apis", org, project),
+		identityURL: fmt.Sprintf("https://vssps.dev.azure.com/%s/_apis", org),
 	}, nil
 }
 
 func (p *AzureDevOpsProvider) doRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	return p.doRequestURL(ctx, method, fmt.Sprintf("%s/%s", p.baseURL, path), body, result)
+}
+
+func (p *AzureDevOpsProvider) doRequestURL(ctx context.Context, method, url string, body interface{}, result interface{}) error {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -77,7 +86,6 @@ func (p *AzureDevOpsProvider) doRequest(ctx context.Context, method, path string
 		bodyReader = bytes.NewBuffer(jsonBody)
 	}
 
-	url := fmt.Sprintf("%s/%s", p.baseURL, path)
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return err
@@ -115,8 +123,21 @@ func (p *AzureDevOpsProvider) GetRepository(ctx context.Context, url string) (*R
 }
 
 func (p *AzureDevOpsProvider) GetLastModificationDate(ctx context.Context, filePath string) (time.Time, types.Commit, error) {
-	// GET /git/repositories/{repositoryId}/commits?searchCriteria.itemPath={filePath}&$top=1
-	path := fmt.Sprintf("git/repositories/%s/commits?searchCriteria.itemPath=%s&$top=1&api-version=7.0", p.repo, filePath)
+	commits, err := p.ListFileCommits(ctx, filePath, 1)
+	if err != nil {
+		return time.Time{}, types.Commit{}, err
+	}
+
+	if len(commits) == 0 {
+		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+	}
+
+	return commits[0].Timestamp, commits[0], nil
+}
+
+func (p *AzureDevOpsProvider) ListFileCommits(ctx context.Context, filePath string, limit int) ([]types.Commit, error) {
+	// GET /git/repositories/{repositoryId}/commits?searchCriteria.itemPath={filePath}&$top={limit}
+	path := fmt.Sprintf("git/repositories/%s/commits?searchCriteria.itemPath=%s&$top=%d&api-version=7.0", p.repo, filePath, limit)
 
 	var result struct {
 		Count int `json:"count"`
@@ -132,23 +153,56 @@ func (p *AzureDevOpsProvider) GetLastModificationDate(ctx context.Context, fileP
 	}
 
 	if err := p.doRequest(ctx, "GET", path, nil, &result); err != nil {
-		return time.Time{}, types.Commit{}, err
+		return nil, err
 	}
 
-	if result.Count == 0 {
-		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+	var commits []types.Commit
+	for _, c := range result.Value {
+		commits = append(commits, types.Commit{
+			Hash:      c.CommitId,
+			Author:    c.Author.Name,
+			Email:     c.Author.Email,
+			Message:   c.Comment,
+			Timestamp: c.Author.Date,
+		})
+	}
+
+	return commits, nil
+}
+
+// ResolveCommitAuthor returns the author's email, which Azure DevOps accepts as the unique name
+// of an identity.
+func (p *AzureDevOpsProvider) ResolveCommitAuthor(ctx context.Context, hash, email string) (string, error) {
+	return email, nil
+}
+
+func (p *AzureDevOpsProvider) GetCommitRename(ctx context.Context, hash, filePath string) (string, bool, error) {
+	// GET /git/repositories/{repositoryId}/commits/{commitId}/changes
+	path := fmt.Sprintf("git/repositories/%s/commits/%s/changes?api-version=7.0", p.repo, hash)
+
+	var result struct {
+		Changes []struct {
+			Item struct {
+				Path string `json:"path"`
+			} `json:"item"`
+			ChangeType       string `json:"changeType"`
+			SourceServerItem string `json:"sourceServerItem"`
+		} `json:"changes"`
 	}
 
-	c := result.Value[0]
-	commit := types.Commit{
-		Hash:      c.CommitId,
-		Author:    c.Author.Name,
-		Email:     c.Author.Email,
-		Message:   c.Comment,
-		Timestamp: c.Author.Date,
+	if err := p.doRequest(ctx, "GET", path, nil, &result); err != nil {
+		return "", false, err
 	}
 
-	return c.Author.Date, commit, nil
+	for _, c := range result.Changes {
+		// Azure DevOps paths are rooted, e.g. "/terraform/main.tf"
+		if strings.TrimPrefix(c.Item.Path, "/") != strings.TrimPrefix(filePath, "/") || !strings.Contains(c.ChangeType, "rename") {
+			continue
+		}
+		// A rename with edits is reported as "edit, rename"
+		return strings.TrimPrefix(c.SourceServerItem, "/"), c.ChangeType == "rename", nil
+	}
+	return "", false, nil
 }
 
 func (p *AzureDevOpsProvider) BranchExists(ctx context.Context, name string) (bool, error) {
@@ -308,6 +362,23 @@ func (p *AzureDevOpsProvider) CreatePullRequest(ctx context.Context, cfg types.P
 		return nil, err
 	}
 
+	if len(cfg.Labels) > 0 {
+		p.logger.Warn("labels are not supported for Azure DevOps, not adding them", zap.Strings("labels", cfg.Labels))
+	}
+
+	// Assignees are added as reviewers, and groups are reviewers like users in Azure DevOps
+	var reviewers []string
+	for _, name := range append(append(append([]string(nil), cfg.Assignees...), cfg.Reviewers...), cfg.TeamReviewers...) {
+		if !slices.Contains(reviewers, name) {
+			reviewers = append(reviewers, name)
+		}
+	}
+	if len(reviewers) > 0 {
+		if err := p.RequestReviewers(ctx, fmt.Sprintf("%d", result.PullRequestId), reviewers); err != nil {
+			p.logger.Error("failed to request reviewers", zap.Error(err))
+		}
+	}
+
 	return &types.PullRequest{
 		ID:        fmt.Sprintf("%d", result.PullRequestId),
 		URL:       result.Url,
@@ -344,18 +415,51 @@ func (p *AzureDevOpsProvider) AssignPullRequest(ctx context.Context, id string,
 	return p.RequestReviewers(ctx, id, assignees)
 }
 
+// RequestReviewers adds users and groups, given by unique name, email or display name, as
+// reviewers. The reviewers API only accepts identity IDs, so each name is resolved first; names
+// that don't match exactly one identity are skipped with a warning.
 func (p *AzureDevOpsProvider) RequestReviewers(ctx context.Context, id string, reviewers []string) error {
-	path := fmt.Sprintf("git/repositories/%s/pullrequests/%s/reviewers?api-version=7.0", p.repo, id)
+	for _, reviewer := range reviewers {
+		identityID, err := p.resolveIdentity(ctx, reviewer)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reviewer %s: %w", reviewer, err)
+		}
+		if identityID == "" {
+			p.logger.Warn("could not resolve reviewer to an Azure DevOps identity, not adding it", zap.String("reviewer", reviewer))
+			continue
+		}
 
-	body := make([]map[string]interface{}, len(reviewers))
-	for i, reviewer := range reviewers {
-		body[i] = map[string]interface{}{
-			"uniqueName": reviewer,
-			"vote":       0, // 0 = no vote, 10 = approved, -10 = rejected
+		// PUT /git/repositories/{repositoryId}/pullrequests/{pullRequestId}/reviewers/{reviewerId}
+		path := fmt.Sprintf("git/repositories/%s/pullrequests/%s/reviewers/%s?api-version=7.0", p.repo, id, identityID)
+		body := map[string]interface{}{
+			"vote": 0, // 0 = no vote, 10 = approved, -10 = rejected
+		}
+		if err := p.doRequest(ctx, "PUT", path, body, nil); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	return p.doRequest(ctx, "PUT", path, body, nil)
+// resolveIdentity returns the ID of the user or group with the given name, or an empty string
+// when no single identity matches.
+func (p *AzureDevOpsProvider) resolveIdentity(ctx context.Context, name string) (string, error) {
+	// GET https://vssps.dev.azure.com/{organization}/_apis/identities?searchFilter=General&filterValue={name}
+	requestURL := fmt.Sprintf("%s/identities?searchFilter=General&filterValue=%s&queryMembership=None&api-version=7.0", p.identityURL, url.QueryEscape(name))
+
+	var result struct {
+		Count int `json:"count"`
+		Value []struct {
+			ID string `json:"id"`
+		} `json:"value"`
+	}
+	if err := p.doRequestURL(ctx, "GET", requestURL, nil, &result); err != nil {
+		return "", err
+	}
+	if len(result.Value) != 1 {
+		return "", nil
+	}
+	return result.Value[0].ID, nil
 }
 
 func (p *AzureDevOpsProvider) AddLabels(ctx context.Context, id string, labels []string) error {
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAzureDevOpsProvider_RequestReviewers(t *testing.T) {
+	identities := map[string][]string{
+		"alice@example.com":     {"1111"},
+		`[Infra]\Platform Team`: {"2222"},
+		"bob":                   {"3333", "4444"}, // Ambiguous
+	}
+	reviewers := make(map[string]string) // Request path -> body
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /vssps/_apis/identities", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "General", r.URL.Query().Get("searchFilter"))
+		var value []map[string]string
+		for _, id := range identities[r.URL.Query().Get("filterValue")] {
+			value = append(value, map[string]string{"id": id})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"count": len(value), "value": value}))
+	})
+	mux.HandleFunc("PUT /org/project/_apis/git/repositories/repo/pullrequests/42/reviewers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		reviewers[r.URL.Path] = string(body)
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &AzureDevOpsProvider{
+		client:      server.Client(),
+		repo:        "repo",
+		logger:      zap.NewNop(),
+		baseURL:     server.URL + "/org/project/_apis",
+		identityURL: server.URL + "/vssps/_apis",
+	}
+	err := p.RequestReviewers(context.Background(), "42", []string{"alice@example.com", `[Infra]\Platform Team`, "bob", "ghost"})
+	require.NoError(t, err)
+
+	assert.Len(t, reviewers, 2)
+	for _, id := range []string{"1111", "2222"} {
+		assert.JSONEq(t, `{"vote": 0}`, reviewers["/org/project/_apis/git/repositories/repo/pullrequests/42/reviewers/"+id], id)
+	}
+}
@@ -73,34 +73,81 @@ func (p *GitHubProvider) GetRepository(ctx context.Context, url string) (*Reposi
 
 func (p *GitHubProvider) GetLastModificationDate(ctx context.Context, filePath string) (time.Time, types.Commit, error) {
 	p.logger.Debug("GetLastModificationDate called", zap.String("filePath", filePath))
+	commits, err := p.ListFileCommits(ctx, filePath, 1)
+	if err != nil {
+		return time.Time{}, types.Commit{}, err
+	}
+
+	if len(commits) == 0 {
+		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+	}
+
+	return commits[0].Timestamp, commits[0], nil
+}
+
+func (p *GitHubProvider) ListFileCommits(ctx context.Context, filePath string, limit int) ([]types.Commit, error) {
+	p.logger.Debug("ListFileCommits called", zap.String("filePath", filePath), zap.Int("limit", limit))
 	opts := &github.CommitsListOptions{
 		Path: filePath,
 		ListOptions: github.ListOptions{
-			PerPage: 1,
+			PerPage: min(limit, 100),
 		},
 	}
 
-	commits, _, err := p.client.Repositories.ListCommits(ctx, p.owner, p.repo, opts)
-	if err != nil {
-		return time.Time{}, types.Commit{}, err
-	}
+	var result []types.Commit
+	for {
+		commits, resp, err := p.client.Repositories.ListCommits(ctx, p.owner, p.repo, opts)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(commits) == 0 {
-		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+		for _, commit := range commits {
+			ts := commit.GetCommit().GetCommitter().GetDate().Time
+			result = append(result, types.Commit{
+				Hash:      commit.GetSHA(),
+				Author:    commit.GetCommit().GetAuthor().GetName(),
+				Email:     commit.GetCommit().GetAuthor().GetEmail(),
+				Message:   commit.GetCommit().GetMessage(),
+				Timestamp: ts,
+			})
+			if len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	commit := commits[0]
-	ts := commit.GetCommit().GetCommitter().GetDate().Time
+func (p *GitHubProvider) GetCommitRename(ctx context.Context, hash, filePath string) (string, bool, error) {
+	p.logger.Debug("GetCommitRename called", zap.String("hash", hash), zap.String("filePath", filePath))
+	commit, _, err := p.client.Repositories.GetCommit(ctx, p.owner, p.repo, hash, nil)
+	if err != nil {
+		return "", false, err
+	}
 
-	c := types.Commit{
-		Hash:      commit.GetSHA(),
-		Author:    commit.GetCommit().GetAuthor().GetName(),
-		Email:     commit.GetCommit().GetAuthor().GetEmail(),
-		Message:   commit.GetCommit().GetMessage(),
-		Timestamp: ts,
+	for _, f := range commit.Files {
+		if f.GetFilename() == filePath && f.GetStatus() == "renamed" {
+			return f.GetPreviousFilename(), f.GetChanges() == 0, nil
+		}
 	}
+	return "", false, nil
+}
 
-	return ts, c, nil
+// ResolveCommitAuthor returns the login of the GitHub account linked to the commit's author.
+func (p *GitHubProvider) ResolveCommitAuthor(ctx context.Context, hash, email string) (string, error) {
+	p.logger.Debug("ResolveCommitAuthor called", zap.String("hash", hash))
+	if hash == "" {
+		return "", nil
+	}
+	commit, _, err := p.client.Repositories.GetCommit(ctx, p.owner, p.repo, hash, nil)
+	if err != nil {
+		return "", err
+	}
+	return commit.GetAuthor().GetLogin(), nil
 }
 
 func (p *GitHubProvider) BranchExists(ctx context.Context, name string) (bool, error) {
@@ -233,9 +280,10 @@ func (p *GitHubProvider) CreatePullRequest(ctx context.Context, cfg types.PRConf
 		}
 	}
 
-	if len(cfg.Reviewers) > 0 {
+	if len(cfg.Reviewers) > 0 || len(cfg.TeamReviewers) > 0 {
 		reviewers := github.ReviewersRequest{
-			Reviewers: cfg.Reviewers,
+			Reviewers:     cfg.Reviewers,
+			TeamReviewers: teamSlugs(cfg.TeamReviewers),
 		}
 		_, _, err = p.client.PullRequests.RequestReviewers(ctx, p.owner, p.repo, pr.GetNumber(), reviewers)
 		if err != nil {
@@ -319,3 +367,15 @@ func (p *GitHubProvider) AddComment(ctx context.Context, id string, comment stri
 	_, _, err := p.client.Issues.CreateComment(ctx, p.owner, p.repo, prNumber, c)
 	return err
 }
+
+// teamSlugs strips the organization from org/team names, as GitHub expects team slugs.
+func teamSlugs(teams []string) []string {
+	var slugs []string
+	for _, team := range teams {
+		if _, slug, ok := strings.Cut(team, "/"); ok {
+			team = slug
+		}
+		slugs = append(slugs, team)
+	}
+	return slugs
+}
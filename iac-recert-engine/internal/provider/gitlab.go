@@ -73,33 +73,100 @@ func (p *GitLabProvider) GetRepository(ctx context.Context, url string) (*Reposi
 }
 
 func (p *GitLabProvider) GetLastModificationDate(ctx context.Context, filePath string) (time.Time, types.Commit, error) {
+	commits, err := p.ListFileCommits(ctx, filePath, 1)
+	if err != nil {
+		return time.Time{}, types.Commit{}, err
+	}
+
+	if len(commits) == 0 {
+		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+	}
+
+	return commits[0].Timestamp, commits[0], nil
+}
+
+func (p *GitLabProvider) ListFileCommits(ctx context.Context, filePath string, limit int) ([]types.Commit, error) {
 	// Use the repository commits API
 	opts := &gitlab.ListCommitsOptions{
 		Path: &filePath,
 		ListOptions: gitlab.ListOptions{
-			PerPage: 1,
+			PerPage: int64(min(limit, 100)),
 		},
 	}
 
-	commits, _, err := p.client.Commits.ListCommits(p.project, opts)
-	if err != nil {
-		return time.Time{}, types.Commit{}, err
+	var result []types.Commit
+	for {
+		commits, resp, err := p.client.Commits.ListCommits(p.project, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range commits {
+			result = append(result, types.Commit{
+				Hash:      c.ID,
+				Author:    c.AuthorName,
+				Email:     c.AuthorEmail,
+				Message:   c.Message,
+				Timestamp: *c.CommittedDate,
+			})
+			if len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	if len(commits) == 0 {
-		return time.Time{}, types.Commit{}, fmt.Errorf("no commits found for file: %s", filePath)
+func (p *GitLabProvider) GetCommitRename(ctx context.Context, hash, filePath string) (string, bool, error) {
+	opts := &gitlab.GetCommitDiffOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+		},
 	}
 
-	c := commits[0]
-	commit := types.Commit{
-		Hash:      c.ID,
-		Author:    c.AuthorName,
-		Email:     c.AuthorEmail,
-		Message:   c.Message,
-		Timestamp: *c.CommittedDate,
+	for {
+		diffs, resp, err := p.client.Commits.GetCommitDiff(p.project, hash, opts)
+		if err != nil {
+			return "", false, err
+		}
+
+		for _, d := range diffs {
+			if d.NewPath == filePath && d.RenamedFile {
+				return d.OldPath, d.Diff == "", nil
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return "", false, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	return *c.CommittedDate, commit, nil
+// ResolveCommitAuthor returns the username of the GitLab user with the commit author's email.
+func (p *GitLabProvider) ResolveCommitAuthor(ctx context.Context, hash, email string) (string, error) {
+	if email == "" {
+		return "", nil
+	}
+	opts := &gitlab.ListUsersOptions{
+		Search: &email,
+		ListOptions: gitlab.ListOptions{
+			PerPage: 2,
+		},
+	}
+	users, _, err := p.client.Users.ListUsers(opts)
+	if err != nil {
+		return "", err
+	}
+	// The search also matches names, so only an unambiguous result is trusted
+	if len(users) != 1 {
+		return "", nil
+	}
+	return users[0].Username, nil
 }
 
 func (p *GitLabProvider) BranchExists(ctx context.Context, name string) (bool, error) {
@@ -175,12 +242,27 @@ func (p *GitLabProvider) CreatePullRequest(ctx context.Context, cfg types.PRConf
 		Title:        &cfg.Title,
 		Description:  &cfg.Description,
 	}
+	if assigneeIDs := p.userIDs(cfg.Assignees); len(assigneeIDs) > 0 {
+		opts.AssigneeIDs = &assigneeIDs
+	}
+	if reviewerIDs := p.userIDs(cfg.Reviewers); len(reviewerIDs) > 0 {
+		opts.ReviewerIDs = &reviewerIDs
+	}
+	if len(cfg.Labels) > 0 {
+		opts.Labels = (*gitlab.LabelOptions)(&cfg.Labels)
+	}
 
 	mr, _, err := p.client.MergeRequests.CreateMergeRequest(p.project, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(cfg.TeamReviewers) > 0 {
+		if err := p.requestGroupReviews(mr.IID, cfg.TeamReviewers); err != nil {
+			p.logger.Error("failed to request group reviews", zap.Error(err))
+		}
+	}
+
 	return &types.PullRequest{
 		ID:        strconv.FormatInt(mr.IID, 10),
 		URL:       mr.WebURL,
@@ -222,9 +304,42 @@ func (p *GitLabProvider) AssignPullRequest(ctx context.Context, id string, assig
 		return err
 	}
 
-	// Resolve usernames to user IDs
-	var assigneeIDs []int64
-	for _, username := range assignees {
+	assigneeIDs := p.userIDs(assignees)
+	if len(assigneeIDs) == 0 {
+		return fmt.Errorf("no valid assignees found")
+	}
+
+	opts := &gitlab.UpdateMergeRequestOptions{
+		AssigneeIDs: &assigneeIDs,
+	}
+
+	_, _, err = p.client.MergeRequests.UpdateMergeRequest(p.project, mrIID, opts)
+	return err
+}
+
+func (p *GitLabProvider) RequestReviewers(ctx context.Context, id string, reviewers []string) error {
+	mrIID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	reviewerIDs := p.userIDs(reviewers)
+	if len(reviewerIDs) == 0 {
+		return fmt.Errorf("no valid reviewers found")
+	}
+
+	opts := &gitlab.UpdateMergeRequestOptions{
+		ReviewerIDs: &reviewerIDs,
+	}
+
+	_, _, err = p.client.MergeRequests.UpdateMergeRequest(p.project, mrIID, opts)
+	return err
+}
+
+// userIDs resolves usernames to user IDs, skipping the usernames that don't exist.
+func (p *GitLabProvider) userIDs(usernames []string) []int64 {
+	var ids []int64
+	for _, username := range usernames {
 		// Search for user by username
 		opts := &gitlab.ListUsersOptions{
 			Username: &username,
@@ -237,27 +352,39 @@ func (p *GitLabProvider) AssignPullRequest(ctx context.Context, id string, assig
 			p.logger.Warn("Failed to resolve username to user ID", zap.String("username", username), zap.Error(err))
 			continue
 		}
-		assigneeIDs = append(assigneeIDs, users[0].ID)
+		ids = append(ids, users[0].ID)
 	}
+	return ids
+}
 
-	if len(assigneeIDs) == 0 {
-		return fmt.Errorf("no valid assignees found")
+// requestGroupReviews adds an approval rule requiring one approval from the given groups, by
+// full path (e.g. org/infra). GitLab has no group reviewers, and merge request approval rules
+// require GitLab Premium.
+func (p *GitLabProvider) requestGroupReviews(mrIID int64, groups []string) error {
+	var groupIDs []int64
+	for _, path := range groups {
+		group, _, err := p.client.Groups.GetGroup(path, nil)
+		if err != nil {
+			p.logger.Warn("Failed to resolve group", zap.String("group", path), zap.Error(err))
+			continue
+		}
+		groupIDs = append(groupIDs, group.ID)
 	}
-
-	opts := &gitlab.UpdateMergeRequestOptions{
-		AssigneeIDs: &assigneeIDs,
+	if len(groupIDs) == 0 {
+		return fmt.Errorf("no valid groups found")
 	}
 
-	_, _, err = p.client.MergeRequests.UpdateMergeRequest(p.project, mrIID, opts)
+	name := "Recertification"
+	approvals := int64(1)
+	opts := &gitlab.CreateMergeRequestApprovalRuleOptions{
+		Name:              &name,
+		ApprovalsRequired: &approvals,
+		GroupIDs:          &groupIDs,
+	}
+	_, _, err := p.client.MergeRequestApprovals.CreateApprovalRule(p.project, mrIID, opts)
 	return err
 }
 
-func (p *GitLabProvider) RequestReviewers(ctx context.Context, id string, reviewers []string) error {
-	// GitLab doesn't have the same reviewer system as GitHub
-	// Reviewers are typically assigned as assignees or mentioned in comments
-	return fmt.Errorf("RequestReviewers not supported for GitLab")
-}
-
 func (p *GitLabProvider) AddLabels(ctx context.Context, id string, labels []string) error {
 	mrIID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
@@ -13,6 +13,13 @@ import (
 type GitProvider interface {
 	GetRepository(ctx context.Context, url string) (*Repository, error)
 	GetLastModificationDate(ctx context.Context, filePath string) (time.Time, types.Commit, error)
+	ListFileCommits(ctx context.Context, filePath string, limit int) ([]types.Commit, error)
+	// GetCommitRename returns the path filePath had before the given commit if the commit
+	// renamed it (empty otherwise), and whether the content was left unchanged.
+	GetCommitRename(ctx context.Context, hash, filePath string) (string, bool, error)
+	// ResolveCommitAuthor returns the username of the account that authored a commit, or an
+	// empty string if the author has no known account.
+	ResolveCommitAuthor(ctx context.Context, hash, email string) (string, error)
 
 	CreateBranch(ctx context.Context, name, baseRef string) error
 	BranchExists(ctx context.Context, name string) (bool, error)
@@ -0,0 +1,288 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/risk"
+	"github.com/baldator/iac-recert-engine/internal/types"
+)
+
+// Report summarizes the outcome of a recertification run.
+type Report struct {
+	RunID       string
+	Repository  string
+	GeneratedAt time.Time
+	RepoRoot    string // File paths are shown relative to this directory
+	Results     []types.RecertCheckResult
+
+	UnknownPolicy  string // history.unknown_policy applied to files with unknown history
+	SkippedUnknown int    // Files with unknown history dropped by the skip policy
+
+	Forecast *Forecast // Set for forecast runs
+}
+
+// Forecast lists the files becoming due after AsOf, up to and including Until.
+type Forecast struct {
+	AsOf     time.Time
+	Until    time.Time
+	Upcoming []Upcoming
+}
+
+// Upcoming is a file becoming due within the forecast horizon.
+type Upcoming struct {
+	Result  types.RecertCheckResult
+	DueDate time.Time // NextDueDate, or the expiry of the file's exemption if later
+	Owners  []string  // Assignees resolved for the file, empty when unassigned
+}
+
+// Write renders the report as Markdown and writes it to path.
+func Write(path string, r Report) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(Render(r)), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// Render returns the report as Markdown.
+func Render(r Report) string {
+	var due, unknown, exempted, expired []types.RecertCheckResult
+	for _, res := range r.Results {
+		if res.Exempted {
+			exempted = append(exempted, res)
+			continue
+		}
+		if res.Exemption != nil {
+			expired = append(expired, res)
+		}
+		if res.HistoryUnknown {
+			unknown = append(unknown, res)
+		} else if res.NeedsRecert {
+			due = append(due, res)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].RiskScore > due[j].RiskScore
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Recertification Report\n\n")
+	sb.WriteString(fmt.Sprintf("- Repository: %s\n", r.Repository))
+	sb.WriteString(fmt.Sprintf("- Run ID: %s\n", r.RunID))
+	sb.WriteString(fmt.Sprintf("- Generated: %s\n", r.GeneratedAt.Format(time.RFC3339)))
+	if r.Forecast != nil {
+		sb.WriteString(fmt.Sprintf("- As of: %s\n", r.Forecast.AsOf.Format("2006-01-02")))
+		sb.WriteString(fmt.Sprintf("- Forecast until: %s\n", r.Forecast.Until.Format("2006-01-02")))
+	}
+	sb.WriteString(fmt.Sprintf("- Files checked: %d\n", len(r.Results)+r.SkippedUnknown))
+	sb.WriteString(fmt.Sprintf("- Due for recertification: %d\n", len(due)))
+	sb.WriteString(fmt.Sprintf("- Exempted: %d\n", len(exempted)))
+	sb.WriteString(fmt.Sprintf("- Unknown history: %d\n\n", len(unknown)+r.SkippedUnknown))
+
+	sb.WriteString("## Due for Recertification\n\n")
+	if len(due) == 0 {
+		sb.WriteString("No files are due.\n\n")
+	} else {
+		sb.WriteString("| Path | Pattern | Last Modified | Days Since | Threshold | Risk | Priority | SLA Deadline |\n")
+		sb.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, res := range due {
+			slaDeadline := "-"
+			if !res.SLADeadline.IsZero() {
+				slaDeadline = res.SLADeadline.Format("2006-01-02")
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %d | %.2f | %s | %s |\n",
+				r.displayPath(res.File),
+				res.PatternName,
+				res.File.LastModified.Format("2006-01-02"),
+				res.DaysSince,
+				res.Threshold,
+				res.RiskScore,
+				res.Priority,
+				slaDeadline,
+			))
+		}
+		sb.WriteString("\n")
+
+		var scored []types.RecertCheckResult
+		for _, res := range due {
+			if len(res.RiskBreakdown) > 0 {
+				scored = append(scored, res)
+			}
+		}
+		if len(scored) > 0 {
+			sb.WriteString("## Risk Breakdown\n\n")
+			for _, res := range scored {
+				sb.WriteString(fmt.Sprintf("- %s: %.2f = %s\n", r.displayPath(res.File), res.RiskScore, risk.FormatBreakdown(res.RiskBreakdown)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if r.Forecast != nil {
+		r.writeForecast(&sb)
+	}
+
+	if len(expired) > 0 {
+		sb.WriteString("## Expired Exemptions\n\n")
+		sb.WriteString("These files are no longer exempted. Renew or remove their exemptions.\n\n")
+		r.writeExemptions(&sb, expired)
+	}
+
+	if len(exempted) > 0 {
+		sb.WriteString("## Exemptions\n\n")
+		r.writeExemptions(&sb, exempted)
+	}
+
+	if len(unknown) > 0 || r.SkippedUnknown > 0 {
+		sb.WriteString("## Unknown History\n\n")
+		sb.WriteString("No modification date could be resolved for these files, e.g. because the provider API failed. ")
+		switch r.UnknownPolicy {
+		case "report_only":
+			sb.WriteString("No pull requests were opened for them.\n\n")
+		case "skip":
+			sb.WriteString(fmt.Sprintf("%d files were skipped.\n\n", r.SkippedUnknown))
+		default:
+			sb.WriteString("They were treated as due.\n\n")
+		}
+		if len(unknown) > 0 {
+			sb.WriteString("| Path | Pattern |\n")
+			sb.WriteString("|---|---|\n")
+			for _, res := range unknown {
+				sb.WriteString(fmt.Sprintf("| %s | %s |\n", r.displayPath(res.File), res.PatternName))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// writeForecast renders the upcoming files bucketed by week and by owner, for capacity planning.
+func (r Report) writeForecast(sb *strings.Builder) {
+	f := r.Forecast
+	upcoming := append([]Upcoming(nil), f.Upcoming...)
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DueDate.Before(upcoming[j].DueDate)
+	})
+
+	sb.WriteString("## Forecast\n\n")
+	sb.WriteString(fmt.Sprintf("%d files become due after %s, until %s.\n\n",
+		len(upcoming), f.AsOf.Format("2006-01-02"), f.Until.Format("2006-01-02")))
+	if len(upcoming) == 0 {
+		return
+	}
+
+	var weeks []time.Time
+	perWeek := make(map[time.Time]int)
+	for _, u := range upcoming {
+		week := weekOf(u.DueDate)
+		if perWeek[week] == 0 {
+			weeks = append(weeks, week)
+		}
+		perWeek[week]++
+	}
+	sb.WriteString("### By Week\n\n")
+	sb.WriteString("| Week of | Files |\n")
+	sb.WriteString("|---|---|\n")
+	for _, week := range weeks {
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", week.Format("2006-01-02"), perWeek[week]))
+	}
+	sb.WriteString("\n")
+
+	type ownerLoad struct {
+		owner    string
+		files    int
+		firstDue time.Time
+	}
+	var owners []*ownerLoad
+	perOwner := make(map[string]*ownerLoad)
+	for _, u := range upcoming {
+		owner := ownerName(u.Owners)
+		load, ok := perOwner[owner]
+		if !ok {
+			// Upcoming files are sorted, so the first file of an owner is due first
+			load = &ownerLoad{owner: owner, firstDue: u.DueDate}
+			perOwner[owner] = load
+			owners = append(owners, load)
+		}
+		load.files++
+	}
+	sort.SliceStable(owners, func(i, j int) bool {
+		if owners[i].files != owners[j].files {
+			return owners[i].files > owners[j].files
+		}
+		return owners[i].owner < owners[j].owner
+	})
+	sb.WriteString("### By Owner\n\n")
+	sb.WriteString("| Owner | Files | First Due |\n")
+	sb.WriteString("|---|---|---|\n")
+	for _, load := range owners {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", load.owner, load.files, load.firstDue.Format("2006-01-02")))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("### Upcoming Files\n\n")
+	sb.WriteString("| Path | Pattern | Due Date | Owner |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, u := range upcoming {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			r.displayPath(u.Result.File),
+			u.Result.PatternName,
+			u.DueDate.Format("2006-01-02"),
+			ownerName(u.Owners),
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// weekOf returns the Monday starting the week of t.
+func weekOf(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func ownerName(owners []string) string {
+	if len(owners) == 0 {
+		return "unassigned"
+	}
+	return strings.Join(owners, ", ")
+}
+
+func (r Report) writeExemptions(sb *strings.Builder, results []types.RecertCheckResult) {
+	sb.WriteString("| Path | Pattern | Reason | Approved By | Expires | Source |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+	for _, res := range results {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			r.displayPath(res.File),
+			res.PatternName,
+			res.Exemption.Reason,
+			res.Exemption.ApprovedBy,
+			res.Exemption.ExpiresAt.Format("2006-01-02"),
+			res.Exemption.Source,
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// displayPath returns the repo-relative path of a file, including the resource for Kubernetes units.
+func (r Report) displayPath(file types.FileInfo) string {
+	path := file.Path
+	if r.RepoRoot != "" {
+		if rel, err := filepath.Rel(r.RepoRoot, file.Path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+	}
+	if file.Resource != "" {
+		path = fmt.Sprintf("%s (%s)", path, file.Resource)
+	}
+	return path
+}
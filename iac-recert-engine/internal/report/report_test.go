@@ -0,0 +1,99 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	r := Report{
+		RunID:       "run-1",
+		Repository:  "https://github.com/test/repo",
+		GeneratedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		RepoRoot:    "/scan",
+		Results: []types.RecertCheckResult{
+			{
+				File:        types.FileInfo{Path: "/scan/prod/main.tf", LastModified: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+				PatternName: "terraform",
+				DaysSince:   214,
+				Threshold:   180,
+				Priority:    "High",
+				NeedsRecert: true,
+				SLADeadline: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+				RiskScore:   1.19,
+			},
+			{
+				File:        types.FileInfo{Path: "/scan/dev/main.tf"},
+				PatternName: "terraform",
+				Priority:    "Low",
+			},
+			{
+				File:           types.FileInfo{Path: "/scan/k8s/app.yaml", Resource: "Deployment/web"},
+				PatternName:    "kubernetes",
+				HistoryUnknown: true,
+			},
+		},
+		UnknownPolicy: "report_only",
+	}
+
+	out := Render(r)
+	assert.Contains(t, out, "- Due for recertification: 1\n")
+	assert.Contains(t, out, "| prod/main.tf | terraform | 2024-06-01 | 214 | 180 | 1.19 | High | 2025-01-05 |")
+	assert.NotContains(t, out, "dev/main.tf")
+	assert.Contains(t, out, "## Unknown History")
+	assert.Contains(t, out, "No pull requests were opened for them.")
+	assert.Contains(t, out, "| k8s/app.yaml (Deployment/web) | kubernetes |")
+
+	t.Run("no unknown history section", func(t *testing.T) {
+		out := Render(Report{Results: r.Results[:2]})
+		assert.NotContains(t, out, "## Unknown History")
+	})
+
+	t.Run("exemptions", func(t *testing.T) {
+		exemption := &types.Exemption{Reason: "decommission", ApprovedBy: "jane", ExpiresAt: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Source: ".ice-exemptions.yaml"}
+		out := Render(Report{RepoRoot: "/scan", Results: []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "/scan/legacy/main.tf"}, PatternName: "terraform", Exemption: exemption, Exempted: true},
+			{File: types.FileInfo{Path: "/scan/frozen/main.tf"}, PatternName: "terraform", Exemption: exemption, NeedsRecert: true},
+		}})
+		assert.Contains(t, out, "- Due for recertification: 1\n")
+		assert.Contains(t, out, "- Exempted: 1\n")
+		assert.Contains(t, out, "## Expired Exemptions")
+		assert.Contains(t, out, "| frozen/main.tf | terraform | decommission | jane | 2025-03-31 | .ice-exemptions.yaml |")
+		assert.Contains(t, out, "## Exemptions")
+		assert.Contains(t, out, "| legacy/main.tf | terraform | decommission | jane | 2025-03-31 | .ice-exemptions.yaml |")
+	})
+
+	t.Run("forecast", func(t *testing.T) {
+		due := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+		out := Render(Report{RepoRoot: "/scan", Forecast: &Forecast{
+			AsOf:  due(2025, 1, 1),
+			Until: due(2025, 3, 31),
+			Upcoming: []Upcoming{
+				{Result: types.RecertCheckResult{File: types.FileInfo{Path: "/scan/b.tf"}, PatternName: "terraform"}, DueDate: due(2025, 1, 15), Owners: []string{"alice"}},
+				{Result: types.RecertCheckResult{File: types.FileInfo{Path: "/scan/a.tf"}, PatternName: "terraform"}, DueDate: due(2025, 1, 13), Owners: []string{"bob"}},
+				{Result: types.RecertCheckResult{File: types.FileInfo{Path: "/scan/c.tf"}, PatternName: "terraform"}, DueDate: due(2025, 2, 3), Owners: []string{"alice"}},
+				{Result: types.RecertCheckResult{File: types.FileInfo{Path: "/scan/d.tf"}, PatternName: "terraform"}, DueDate: due(2025, 2, 4)},
+			},
+		}})
+		assert.Contains(t, out, "- As of: 2025-01-01\n- Forecast until: 2025-03-31\n")
+		assert.Contains(t, out, "4 files become due after 2025-01-01, until 2025-03-31.")
+		// Weeks start on Monday
+		assert.Contains(t, out, "| Week of | Files |\n|---|---|\n| 2025-01-13 | 2 |\n| 2025-02-03 | 2 |\n")
+		assert.Contains(t, out, "| alice | 2 | 2025-01-15 |\n| bob | 1 | 2025-01-13 |\n| unassigned | 1 | 2025-02-04 |\n")
+		assert.Contains(t, out, "| a.tf | terraform | 2025-01-13 | bob |\n| b.tf | terraform | 2025-01-15 | alice |\n")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "reports", "recert.md")
+		require.NoError(t, Write(path, r))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, out, string(content))
+	})
+}
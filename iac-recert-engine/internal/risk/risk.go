@@ -0,0 +1,108 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Scorer computes the risk score of a recertification result. The score replaces the overdue
+// ratio when picking the priority bucket, so a scorer should return the ratio for a result of
+// average risk.
+type Scorer interface {
+	Score(result types.RecertCheckResult, pattern config.Pattern, relPath string) (float64, []types.RiskFactor)
+}
+
+// Default caps at which a signal reaches its full weight.
+const (
+	defaultSizeCap    = 100 * 1024 // bytes
+	defaultChurnCap   = 10         // commits in the last 90 days
+	defaultAuthorsCap = 5          // distinct authors in the last year
+)
+
+// Model is the built-in Scorer: the overdue ratio multiplied by the pattern weight, the weight
+// of the first matching environment, and a factor of 1 to 1+weight per signal.
+type Model struct {
+	cfg config.RiskConfig
+}
+
+func NewModel(cfg config.RiskConfig) (*Model, error) {
+	for _, env := range cfg.Environments {
+		for _, p := range env.Paths {
+			if !doublestar.ValidatePattern(p) {
+				return nil, fmt.Errorf("invalid path %q in risk environment %s", p, env.Name)
+			}
+		}
+	}
+	return &Model{cfg: cfg}, nil
+}
+
+func (m *Model) Score(result types.RecertCheckResult, pattern config.Pattern, relPath string) (float64, []types.RiskFactor) {
+	ratio := float64(result.DaysSince) / float64(result.Threshold)
+	factors := []types.RiskFactor{
+		{Name: "overdue", Detail: fmt.Sprintf("%d/%d days", result.DaysSince, result.Threshold), Factor: ratio},
+	}
+
+	if pattern.RiskWeight > 0 && pattern.RiskWeight != 1 {
+		factors = append(factors, types.RiskFactor{Name: "pattern", Detail: pattern.Name, Factor: pattern.RiskWeight})
+	}
+
+	for _, env := range m.cfg.Environments {
+		if matchAny(env.Paths, relPath) {
+			factors = append(factors, types.RiskFactor{Name: "environment", Detail: env.Name, Factor: env.Weight})
+			break
+		}
+	}
+
+	file := result.File
+	if f, ok := signal(m.cfg.Size, defaultSizeCap, float64(file.Size)); ok {
+		factors = append(factors, types.RiskFactor{Name: "size", Detail: fmt.Sprintf("%.1f KB", float64(file.Size)/1024), Factor: f})
+	}
+	if f, ok := signal(m.cfg.Churn, defaultChurnCap, float64(file.RecentCommits)); ok {
+		factors = append(factors, types.RiskFactor{Name: "churn", Detail: fmt.Sprintf("%d commits in 90 days", file.RecentCommits), Factor: f})
+	}
+	if f, ok := signal(m.cfg.Authors, defaultAuthorsCap, float64(file.RecentAuthors)); ok {
+		factors = append(factors, types.RiskFactor{Name: "authors", Detail: fmt.Sprintf("%d authors in the last year", file.RecentAuthors), Factor: f})
+	}
+
+	score := 1.0
+	for _, f := range factors {
+		score *= f.Factor
+	}
+	return score, factors
+}
+
+// signal returns 1 + weight * min(value/cap, 1), and false when the signal is disabled.
+func signal(cfg config.RiskSignal, defaultCap, value float64) (float64, bool) {
+	if cfg.Weight == 0 {
+		return 1, false
+	}
+	limit := cfg.Cap
+	if limit == 0 {
+		limit = defaultCap
+	}
+	return 1 + cfg.Weight*math.Min(value/limit, 1), true
+}
+
+func matchAny(patterns []string, relPath string) bool {
+	for _, p := range patterns {
+		if ok, _ := doublestar.Match(p, filepath.ToSlash(relPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatBreakdown renders the factors of a score, e.g. "overdue 1.25 (75/60 days) × environment 1.50 (prod)".
+func FormatBreakdown(factors []types.RiskFactor) string {
+	parts := make([]string, 0, len(factors))
+	for _, f := range factors {
+		parts = append(parts, fmt.Sprintf("%s %.2f (%s)", f.Name, f.Factor, f.Detail))
+	}
+	return strings.Join(parts, " × ")
+}
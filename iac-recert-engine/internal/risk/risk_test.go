@@ -0,0 +1,58 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_Score(t *testing.T) {
+	model, err := NewModel(config.RiskConfig{
+		Enabled: true,
+		Environments: []config.RiskEnvironment{
+			{Name: "prod", Paths: []string{"terraform/prod/**"}, Weight: 1.5},
+			{Name: "dev", Paths: []string{"terraform/dev/**"}, Weight: 0.5},
+		},
+		Churn:   config.RiskSignal{Weight: 0.5, Cap: 4},
+		Authors: config.RiskSignal{Weight: 1},
+	})
+	require.NoError(t, err)
+
+	result := types.RecertCheckResult{
+		File:      types.FileInfo{Size: 2048, RecentCommits: 2, RecentAuthors: 5},
+		DaysSince: 90,
+		Threshold: 60,
+	}
+	pattern := config.Pattern{Name: "iam", RiskWeight: 2}
+
+	score, factors := model.Score(result, pattern, "terraform/prod/iam.tf")
+	// 1.5 overdue × 2 pattern × 1.5 prod × 1.25 churn × 2 authors
+	assert.InDelta(t, 11.25, score, 1e-9)
+	require.Len(t, factors, 5)
+	assert.Equal(t, "environment", factors[2].Name)
+	assert.Equal(t, "prod", factors[2].Detail)
+
+	t.Run("defaults score the overdue ratio", func(t *testing.T) {
+		model, err := NewModel(config.RiskConfig{Enabled: true})
+		require.NoError(t, err)
+		score, factors := model.Score(result, config.Pattern{Name: "iam"}, "terraform/dev/main.tf")
+		assert.InDelta(t, 1.5, score, 1e-9)
+		assert.Len(t, factors, 1)
+	})
+
+	t.Run("invalid environment path", func(t *testing.T) {
+		_, err := NewModel(config.RiskConfig{Environments: []config.RiskEnvironment{{Name: "prod", Paths: []string{"[prod"}, Weight: 1}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatBreakdown(t *testing.T) {
+	out := FormatBreakdown([]types.RiskFactor{
+		{Name: "overdue", Detail: "75/60 days", Factor: 1.25},
+		{Name: "environment", Detail: "prod", Factor: 1.5},
+	})
+	assert.Equal(t, "overdue 1.25 (75/60 days) × environment 1.50 (prod)", out)
+}
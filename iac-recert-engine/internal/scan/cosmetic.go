@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// semanticallyEqual reports whether two versions of a file differ only in whitespace or comments.
+// YAML and JSON are compared structurally; HCL has its comments and whitespace stripped; other
+// files are compared with whitespace removed.
+func semanticallyEqual(path string, before, after []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		a, errA := parseYAMLDocuments(before)
+		b, errB := parseYAMLDocuments(after)
+		if errA == nil && errB == nil {
+			return reflect.DeepEqual(a, b)
+		}
+	case ".json":
+		var a, b any
+		errA := json.Unmarshal(before, &a)
+		errB := json.Unmarshal(after, &b)
+		if errA == nil && errB == nil {
+			return reflect.DeepEqual(a, b)
+		}
+	case ".tf", ".tfvars", ".hcl":
+		return stripHCL(before) == stripHCL(after)
+	}
+	return stripWhitespace(string(before)) == stripWhitespace(string(after))
+}
+
+// parseYAMLDocuments decodes every document of a YAML stream, dropping comments and formatting.
+func parseYAMLDocuments(content []byte) ([]any, error) {
+	var docs []any
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// stripHCL removes `#`, `//` and `/* */` comments and all whitespace outside string literals and
+// heredocs. Template interpolations (${...} and %{...}) within strings are code, so quotes
+// nested in them open strings of their own and the enclosing string resumes once they close.
+func stripHCL(content []byte) string {
+	var sb strings.Builder
+	src := string(content)
+	inString := false
+	var interpolations []int // Brace depth of each open interpolation, innermost last
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			sb.WriteByte(c)
+			switch {
+			case c == '\\' && i+1 < len(src):
+				i++
+				sb.WriteByte(src[i])
+			case (c == '$' || c == '%') && i+1 < len(src) && src[i+1] == c:
+				// $${ and %%{ are escaped, literal text
+				i++
+				sb.WriteByte(src[i])
+			case (c == '$' || c == '%') && i+1 < len(src) && src[i+1] == '{':
+				i++
+				sb.WriteByte(src[i])
+				interpolations = append(interpolations, 1)
+				inString = false
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		if top := len(interpolations) - 1; top >= 0 && (c == '{' || c == '}') {
+			sb.WriteByte(c)
+			if c == '{' {
+				interpolations[top]++
+			} else if interpolations[top]--; interpolations[top] == 0 {
+				interpolations = interpolations[:top]
+				inString = true
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			sb.WriteByte(c)
+		case c == '<' && strings.HasPrefix(src[i:], "<<"):
+			end := heredocEnd(src, i)
+			if end < 0 {
+				sb.WriteByte(c)
+				continue
+			}
+			// Heredoc bodies are kept verbatim, comment markers and whitespace included
+			sb.WriteString(src[i:end])
+			i = end - 1
+		case c == '#' || (c == '/' && i+1 < len(src) && src[i+1] == '/'):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			// Whitespace is not significant in HCL outside strings and heredocs
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// heredocEnd returns the index just past the closing marker of the heredoc (<<EOF or <<-EOF)
+// starting at i, or -1 if no heredoc starts there. An unterminated heredoc runs to the end.
+func heredocEnd(src string, i int) int {
+	j := i + 2
+	if j < len(src) && src[j] == '-' {
+		j++
+	}
+	start := j
+	for j < len(src) && (src[j] == '_' || src[j] == '-' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+		j++
+	}
+	marker := src[start:j]
+	if marker == "" {
+		return -1
+	}
+	eol := strings.IndexByte(src[j:], '\n')
+	if eol < 0 || strings.TrimSpace(src[j:j+eol]) != "" {
+		return -1
+	}
+	pos := j + eol + 1
+	for pos < len(src) {
+		next := strings.IndexByte(src[pos:], '\n')
+		line := src[pos:]
+		if next >= 0 {
+			line = src[pos : pos+next]
+		}
+		if strings.TrimSpace(line) == marker {
+			return pos + len(line)
+		}
+		if next < 0 {
+			break
+		}
+		pos += next + 1
+	}
+	return len(src)
+}
+
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemanticallyEqual(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		before string
+		after  string
+		want   bool
+	}{
+		{
+			name:   "hcl reformat and comments",
+			path:   "main.tf",
+			before: "resource \"aws_s3_bucket\" \"b\" {\n  bucket = \"logs\"\n}\n",
+			after:  "# Logs bucket\nresource \"aws_s3_bucket\" \"b\" {\n    bucket    = \"logs\" // name\n  /* block\n  comment */\n}\n",
+			want:   true,
+		},
+		{
+			name:   "hcl value change",
+			path:   "main.tf",
+			before: "bucket = \"logs\"\n",
+			after:  "bucket = \"audit\"\n",
+			want:   false,
+		},
+		{
+			name:   "hcl comment markers inside strings are kept",
+			path:   "main.tf",
+			before: "url = \"https://example.com/#a\"\n",
+			after:  "url = \"https://example.com/#b\"\n",
+			want:   false,
+		},
+		{
+			name:   "hcl heredoc lines starting with comment markers are content",
+			path:   "main.tf",
+			before: "user_data = <<-EOF\n  #!/bin/bash\n  # enable audit\n  auditctl -e 1\n  EOF\n",
+			after:  "user_data = <<-EOF\n  #!/bin/bash\n  # enable audit\n  auditctl -e 0\n  EOF\n",
+			want:   false,
+		},
+		{
+			name:   "hcl heredoc whitespace is kept",
+			path:   "main.tf",
+			before: "policy = <<EOF\n# allow read\nread only\nEOF\n",
+			after:  "policy = <<EOF\n# allow read\nread  only\nEOF\n",
+			want:   false,
+		},
+		{
+			name:   "hcl comments around heredoc",
+			path:   "main.tf",
+			before: "policy = <<EOF\n# allow read\nEOF\n",
+			after:  "# policy\npolicy   = <<EOF\n# allow read\nEOF\n# end\n",
+			want:   true,
+		},
+		{
+			name:   "hcl quotes nested in interpolations",
+			path:   "main.tf",
+			before: "subnets = \"${join(\", \", var.subnets)}\"\n",
+			after:  "subnets = \"${join(\",\", var.subnets)}\"\n",
+			want:   false,
+		},
+		{
+			name:   "hcl whitespace inside interpolations",
+			path:   "main.tf",
+			before: "name = \"${var.env}-${lookup(var.names, \"app\", \"x\")} logs\"\n",
+			after:  "name = \"${ var.env }-${lookup(var.names,\n  \"app\", \"x\")} logs\"\n",
+			want:   true,
+		},
+		{
+			name:   "hcl escaped interpolation is literal text",
+			path:   "main.tf",
+			before: "cmd = \"echo $${ HOME }\"\n",
+			after:  "cmd = \"echo $${HOME}\"\n",
+			want:   false,
+		},
+		{
+			name:   "yaml reindent and comments",
+			path:   "app.yaml",
+			before: "kind: Service\nspec:\n  ports:\n  - port: 80\n",
+			after:  "# service\nkind: Service\nspec:\n    ports:\n        - port: 80 # http\n",
+			want:   true,
+		},
+		{
+			name:   "yaml value change",
+			path:   "app.yml",
+			before: "replicas: 2\n",
+			after:  "replicas: 3\n",
+			want:   false,
+		},
+		{
+			name:   "json whitespace",
+			path:   "policy.json",
+			before: "{\"a\": [1, 2]}",
+			after:  "{\n  \"a\": [\n    1,\n    2\n  ]\n}\n",
+			want:   true,
+		},
+		{
+			name:   "other files compare without whitespace",
+			path:   "script.sh",
+			before: "echo hi\n",
+			after:  "echo   hi\n\n",
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, semanticallyEqual(tt.path, []byte(tt.before), []byte(tt.after)))
+		})
+	}
+}
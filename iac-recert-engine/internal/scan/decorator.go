@@ -0,0 +1,117 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// decoratorTimestampLayouts are the formats accepted for the {timestamp} placeholder.
+// The engine writes RFC 3339; plain dates allow hand-written decorators.
+var decoratorTimestampLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// DecoratorRegexp builds a regular expression matching the rendered line of a decorator that
+// contains the {timestamp} placeholder. The timestamp is captured in the first group, and the
+// trailing newline is consumed so the match can be used to remove a stale decorator.
+func DecoratorRegexp(decorator string) (*regexp.Regexp, error) {
+	var line string
+	for _, l := range strings.Split(decorator, "\n") {
+		if strings.Contains(l, "{timestamp}") {
+			line = l
+			break
+		}
+	}
+	if line == "" {
+		return nil, fmt.Errorf("decorator %q has no {timestamp} placeholder", decorator)
+	}
+
+	parts := strings.SplitN(line, "{timestamp}", 2)
+	expr := `(?m)^[ \t]*` + regexp.QuoteMeta(strings.TrimSpace(parts[0])) +
+		`[ \t]*(\S+)[ \t]*` + regexp.QuoteMeta(strings.TrimSpace(parts[1])) + `[ \t]*\r?$\n?`
+	return regexp.Compile(expr)
+}
+
+// parseDecoratorDate returns the most recent timestamp found in the decorator lines of content.
+func parseDecoratorDate(re *regexp.Regexp, content []byte) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, m := range re.FindAllSubmatch(content, -1) {
+		for _, layout := range decoratorTimestampLayouts {
+			ts, err := time.Parse(layout, string(m[1]))
+			if err != nil {
+				continue
+			}
+			if !found || ts.After(latest) {
+				latest = ts
+				found = true
+			}
+			break
+		}
+	}
+	return latest, found
+}
+
+// readDecoratorDate reads the files of a unit and returns the most recent decorator timestamp.
+// For a Kubernetes resource only the decorator within the object's own document counts.
+func readDecoratorDate(re *regexp.Regexp, paths []string, resource string) (time.Time, bool, error) {
+	var latest time.Time
+	found := false
+	for _, p := range paths {
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if resource != "" {
+			doc, ok := findK8sResource(content, resource)
+			if !ok {
+				continue
+			}
+			content = []byte(doc)
+		}
+		if ts, ok := parseDecoratorDate(re, content); ok && (!found || ts.After(latest)) {
+			latest = ts
+			found = true
+		}
+	}
+	return latest, found, nil
+}
+
+// DecorateResource replaces the stale decorator of the Kubernetes object with the given ID and
+// puts the rendered decorator at the top of the object's document, so that the change falls
+// within the object's own line range. The other objects of the manifest are left untouched.
+func DecorateResource(decorator, rendered, content, resource string) (string, bool) {
+	resources, err := splitK8sResources([]byte(content))
+	if err != nil {
+		return content, false
+	}
+	for _, r := range resources {
+		if r.ID != resource {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+		doc := rendered + StripDecorator(decorator, r.Content+"\n")
+		decorated := append([]string(nil), lines[:r.StartLine-1]...)
+		decorated = append(decorated, strings.Split(strings.TrimSuffix(doc, "\n"), "\n")...)
+		decorated = append(decorated, lines[r.EndLine:]...)
+		return strings.Join(decorated, "\n"), true
+	}
+	return content, false
+}
+
+// StripDecorator removes rendered decorator lines from content so a fresh one can be applied.
+// Only lines carrying a valid timestamp are removed.
+func StripDecorator(decorator, content string) string {
+	re, err := DecoratorRegexp(decorator)
+	if err != nil {
+		// Without a placeholder the decorator is static text at the top of the file
+		return strings.TrimPrefix(content, decorator)
+	}
+	return re.ReplaceAllStringFunc(content, func(line string) string {
+		if _, ok := parseDecoratorDate(re, []byte(line)); ok {
+			return ""
+		}
+		return line
+	})
+}
@@ -0,0 +1,82 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoratorRegexp(t *testing.T) {
+	re, err := DecoratorRegexp("# Last Recertification: {timestamp}\n")
+	require.NoError(t, err)
+
+	content := []byte("# Last Recertification: 2024-03-01T10:00:00Z\nresource \"x\" \"y\" {}\n# Last Recertification: 2023-01-01\n")
+	ts, ok := parseDecoratorDate(re, content)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), ts)
+
+	_, ok = parseDecoratorDate(re, []byte("# Last Recertification: soon\n"))
+	assert.False(t, ok)
+
+	_, err = DecoratorRegexp("# Reviewed\n")
+	assert.Error(t, err)
+}
+
+func TestStripDecorator(t *testing.T) {
+	decorator := "// Last Recertification: {timestamp}\n"
+	content := "// Last Recertification: 2024-03-01T10:00:00Z\npackage main\n// Last Recertification: pending\n"
+	assert.Equal(t, "package main\n// Last Recertification: pending\n", StripDecorator(decorator, content))
+
+	assert.Equal(t, "body\n", StripDecorator("# Static\n", "# Static\nbody\n"))
+}
+
+func TestDueReference(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	certified := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, certified, dueReference("", modified, certified))
+	assert.Equal(t, certified, dueReference("latest", modified, certified))
+	assert.Equal(t, modified, dueReference("modification", modified, certified))
+	assert.Equal(t, certified, dueReference("certification", modified, certified))
+	assert.Equal(t, modified, dueReference("certification", modified, time.Time{}))
+	assert.Equal(t, modified, dueReference("earliest", modified, certified))
+	assert.Equal(t, modified, dueReference("earliest", modified, time.Time{}))
+}
+
+func TestDecorateResource(t *testing.T) {
+	decorator := "# Last Recertification: {timestamp}\n"
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Last Recertification: 2024-01-01
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`
+	decorated, ok := DecorateResource(decorator, "# Last Recertification: 2025-06-01\n", content, "ConfigMap/settings")
+	require.True(t, ok)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Last Recertification: 2025-06-01
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`, decorated)
+
+	decorated, ok = DecorateResource(decorator, "# Last Recertification: 2025-06-01\n", decorated, "Service/web")
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(decorated, "# Last Recertification: 2025-06-01\napiVersion: v1\nkind: Service\n"))
+
+	_, ok = DecorateResource(decorator, "", content, "Secret/missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,95 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// exemptionFile is the YAML layout of an exemptions file.
+type exemptionFile struct {
+	Exemptions []struct {
+		Paths      []string `yaml:"paths"`
+		Reason     string   `yaml:"reason"`
+		ApprovedBy string   `yaml:"approved_by"`
+		ExpiresAt  string   `yaml:"expires_at"`
+	} `yaml:"exemptions"`
+}
+
+// LoadExemptions reads an exemptions file. Every entry needs paths, a reason, an approver and an
+// expiry, given as a date (the exemption applies through that day, UTC) or an RFC 3339 timestamp.
+func LoadExemptions(path string) ([]types.Exemption, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exemptions file: %w", err)
+	}
+	var file exemptionFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse exemptions file %s: %w", path, err)
+	}
+
+	exemptions := make([]types.Exemption, 0, len(file.Exemptions))
+	for i, entry := range file.Exemptions {
+		if len(entry.Paths) == 0 || entry.Reason == "" || entry.ApprovedBy == "" || entry.ExpiresAt == "" {
+			return nil, fmt.Errorf("exemption %d in %s: paths, reason, approved_by and expires_at are required", i+1, path)
+		}
+		for _, p := range entry.Paths {
+			if !doublestar.ValidatePattern(p) {
+				return nil, fmt.Errorf("exemption %d in %s: invalid path %q", i+1, path, p)
+			}
+		}
+		expiresAt, err := parseExpiry(entry.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("exemption %d in %s: %w", i+1, path, err)
+		}
+		exemptions = append(exemptions, types.Exemption{
+			Paths:      entry.Paths,
+			Reason:     entry.Reason,
+			ApprovedBy: entry.ApprovedBy,
+			ExpiresAt:  expiresAt,
+			Source:     path,
+		})
+	}
+	return exemptions, nil
+}
+
+func parseExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expires_at %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// matchExemption returns the exemption with a path matching one of paths that expires last, so
+// a renewal in one file overrides an expired entry in another. Ties go to the first match.
+func matchExemption(exemptions []types.Exemption, paths ...string) *types.Exemption {
+	var match *types.Exemption
+	for i := range exemptions {
+		if match != nil && !exemptions[i].ExpiresAt.After(match.ExpiresAt) {
+			continue
+		}
+		if matchesAny(exemptions[i].Paths, paths) {
+			match = &exemptions[i]
+		}
+	}
+	return match
+}
+
+func matchesAny(patterns, paths []string) bool {
+	for _, pattern := range patterns {
+		for _, p := range paths {
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadExemptions(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "exemptions.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		path := write(t, `exemptions:
+  - paths: ["terraform/legacy/**"]
+    reason: "Decommissioned in Q1"
+    approved_by: "jane.doe"
+    expires_at: 2025-03-31
+  - paths: ["k8s/batch/*.yaml", "k8s/cron/*.yaml"]
+    reason: "Frozen during migration"
+    approved_by: "platform-team"
+    expires_at: "2025-06-30T12:00:00Z"
+`)
+		got, err := LoadExemptions(path)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, []string{"terraform/legacy/**"}, got[0].Paths)
+		assert.Equal(t, "Decommissioned in Q1", got[0].Reason)
+		assert.Equal(t, "jane.doe", got[0].ApprovedBy)
+		assert.Equal(t, path, got[0].Source)
+		// A date applies through the end of the day
+		assert.False(t, got[0].Expired(time.Date(2025, 3, 31, 23, 59, 0, 0, time.UTC)))
+		assert.True(t, got[0].Expired(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)))
+
+		assert.Equal(t, time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC), got[1].ExpiresAt)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		path := write(t, "exemptions:\n  - paths: [\"a/**\"]\n    reason: \"x\"\n    expires_at: 2025-01-01\n")
+		_, err := LoadExemptions(path)
+		assert.ErrorContains(t, err, "exemption 1")
+	})
+
+	t.Run("invalid expiry", func(t *testing.T) {
+		path := write(t, "exemptions:\n  - paths: [\"a/**\"]\n    reason: \"x\"\n    approved_by: \"y\"\n    expires_at: next year\n")
+		_, err := LoadExemptions(path)
+		assert.ErrorContains(t, err, "invalid expires_at")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		path := write(t, "exemptions:\n  - paths: [\"a/[\"]\n    reason: \"x\"\n    approved_by: \"y\"\n    expires_at: 2025-01-01\n")
+		_, err := LoadExemptions(path)
+		assert.ErrorContains(t, err, "invalid path")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadExemptions(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
@@ -12,14 +12,28 @@ import (
 )
 
 type Scanner struct {
-	logger  *zap.Logger
-	repoURL string
+	logger       *zap.Logger
+	repoURL      string
+	history      config.HistoryConfig
+	nestedIgnore bool
+	stats        ScanStats
 }
 
-func NewScanner(repoURL string, logger *zap.Logger) *Scanner {
+// ScanStats summarizes what the last Scan excluded through .iceignore files.
+type ScanStats struct {
+	IgnoreFiles  []string // .iceignore files applied, relative to the repo root
+	IgnoredFiles int      // Files matching a pattern that were excluded by .iceignore
+	IgnoredDirs  int      // Directories excluded by .iceignore, which are not walked
+}
+
+// NewScanner creates a Scanner. With nestedIgnore, .iceignore files are read from nested
+// directories as well as from the repository root.
+func NewScanner(repoURL string, history config.HistoryConfig, nestedIgnore bool, logger *zap.Logger) *Scanner {
 	return &Scanner{
-		logger:  logger,
-		repoURL: repoURL,
+		logger:       logger,
+		repoURL:      repoURL,
+		history:      history,
+		nestedIgnore: nestedIgnore,
 	}
 }
 
@@ -32,8 +46,13 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 
 	s.logger.Debug("cloning repository", zap.String("url", s.repoURL), zap.String("temp_dir", tempDir))
 
-	// Clone the repository
-	cmd := exec.Command("git", "clone", "--depth", "1", s.repoURL, tempDir)
+	// Clone the repository. Local history analysis needs the full history rather
+	// than a shallow clone.
+	args := []string{"clone", "--depth", "1", s.repoURL, tempDir}
+	if needsLocalHistory(patterns, s.history) {
+		args = []string{"clone", s.repoURL, tempDir}
+	}
+	cmd := exec.Command("git", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		// Cleanup on error
 		os.RemoveAll(tempDir)
@@ -43,8 +62,12 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 	s.logger.Debug("repository cloned successfully", zap.String("temp_dir", tempDir))
 
 	// Scan the cloned repository
+	s.stats = ScanStats{}
+	ignore := &ignoreMatcher{}
 	var files []types.FileInfo
 	seen := make(map[string]bool)
+	modules := make(map[string]*types.FileInfo)
+	var moduleDirs []string
 
 	s.logger.Debug("starting file scan", zap.String("root", root), zap.String("scan_dir", tempDir), zap.Int("patterns", len(patterns)))
 
@@ -54,10 +77,6 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 			return err
 		}
 
-		if info.IsDir() {
-			return nil
-		}
-
 		// Get relative path
 		relPath, err := filepath.Rel(tempDir, path)
 		if err != nil {
@@ -67,6 +86,32 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 		// Normalize to forward slashes for consistent matching
 		relPath = filepath.ToSlash(relPath)
 
+		if info.IsDir() {
+			// Directories are visited before their contents, so nested .iceignore files
+			// are loaded after their parents'. Nothing below an ignored directory can be
+			// re-included, so it is not walked.
+			dir := relPath
+			if dir == "." {
+				dir = ""
+			} else if ignore.ignoredDir(dir) {
+				s.stats.IgnoredDirs++
+				s.logger.Debug("skipping directory excluded by ignore file", zap.String("dir", dir))
+				return filepath.SkipDir
+			}
+			if dir != "" && !s.nestedIgnore {
+				return nil
+			}
+			loaded, err := ignore.load(tempDir, dir)
+			if err != nil {
+				return err
+			}
+			if loaded {
+				s.stats.IgnoreFiles = append(s.stats.IgnoreFiles, filepath.ToSlash(filepath.Join(dir, IgnoreFileName)))
+				s.logger.Debug("loaded ignore file", zap.String("dir", dir))
+			}
+			return nil
+		}
+
 		s.logger.Debug("checking file during scan", zap.String("file", relPath))
 
 		// Check if file matches any pattern
@@ -77,14 +122,59 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 		}
 
 		if matchedPattern != nil {
+			if ignore.ignored(relPath) {
+				s.stats.IgnoredFiles++
+				s.logger.Debug("skipping file excluded by ignore file", zap.String("file", relPath), zap.String("pattern", matchedPattern.Name))
+				return nil
+			}
+
 			if seen[path] {
 				s.logger.Debug("skipping duplicate file", zap.String("file", path))
 				return nil
 			}
 
+			if matchedPattern.Mode == "terraform_module" {
+				// Collapse the file into its containing module directory
+				dir := filepath.Dir(path)
+				module, ok := modules[dir]
+				if !ok {
+					module = &types.FileInfo{Path: dir, RelPath: filepath.ToSlash(filepath.Dir(relPath))}
+					modules[dir] = module
+					moduleDirs = append(moduleDirs, dir)
+				}
+				module.Members = append(module.Members, path)
+				module.Size += info.Size()
+				seen[path] = true
+				s.logger.Debug("added file to terraform module", zap.String("file", relPath), zap.String("module", dir), zap.String("pattern", matchedPattern.Name))
+				return nil
+			}
+
+			if matchedPattern.Mode == "kubernetes_resource" {
+				resources, err := s.readK8sResources(path)
+				if err != nil {
+					s.logger.Warn("failed to split kubernetes resources, treating as a single file", zap.String("file", relPath), zap.Error(err))
+				}
+				if len(resources) > 0 {
+					for _, r := range resources {
+						files = append(files, types.FileInfo{
+							Path:      path,
+							RelPath:   relPath,
+							Size:      int64(len(r.Content)),
+							Resource:  r.ID,
+							StartLine: r.StartLine,
+							EndLine:   r.EndLine,
+						})
+						s.logger.Debug("added kubernetes resource to scan results", zap.String("file", relPath), zap.String("resource", r.ID), zap.Int("start_line", r.StartLine), zap.Int("end_line", r.EndLine))
+					}
+					seen[path] = true
+					return nil
+				}
+			}
+
 			files = append(files, types.FileInfo{
-				Path: path,
-				Size: info.Size(),
+				Path:    path,
+				RelPath: relPath,
+				Size:    info.Size(),
 			})
 			seen[path] = true
 			s.logger.Debug("added file to scan results", zap.String("file", relPath), zap.String("pattern", matchedPattern.Name), zap.Int64("size", info.Size()))
@@ -94,9 +184,43 @@ func (s *Scanner) Scan(root string, patterns []config.Pattern) ([]types.FileInfo
 	})
 
 	if err != nil {
+		os.RemoveAll(tempDir)
 		return nil, "", fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	s.logger.Debug("scan completed", zap.Int("total_files", len(files)))
+	for _, dir := range moduleDirs {
+		files = append(files, *modules[dir])
+		s.logger.Debug("added terraform module to scan results", zap.String("module", dir), zap.Int("members", len(modules[dir].Members)))
+	}
+
+	s.logger.Debug("scan completed", zap.Int("total_files", len(files)), zap.Int("ignored_files", s.stats.IgnoredFiles), zap.Int("ignored_dirs", s.stats.IgnoredDirs))
 	return files, tempDir, nil
 }
+
+// Stats returns the statistics of the last Scan.
+func (s *Scanner) Stats() ScanStats {
+	return s.stats
+}
+
+// readK8sResources reads a manifest and splits it into its Kubernetes objects.
+func (s *Scanner) readK8sResources(path string) ([]k8sResource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return splitK8sResources(content)
+}
+
+// needsLocalHistory reports whether files are dated from the local git history, either
+// because of the history configuration or because a pattern recertifies Kubernetes resources.
+func needsLocalHistory(patterns []config.Pattern, history config.HistoryConfig) bool {
+	if history.Source == "local" || history.IgnoreCosmeticChanges {
+		return true
+	}
+	for _, p := range patterns {
+		if p.Enabled && p.Mode == "kubernetes_resource" {
+			return true
+		}
+	}
+	return false
+}
@@ -43,7 +43,7 @@ func TestScanner_Scan(t *testing.T) {
 	require.NoError(t, exec.Command("git", "-C", tmpDir, "commit", "-m", "Initial commit").Run())
 
 	logger := zap.NewNop()
-	scanner := NewScanner(tmpDir, logger)
+	scanner := NewScanner(tmpDir, config.HistoryConfig{}, false, logger)
 
 	tests := []struct {
 		name     string
@@ -93,6 +93,21 @@ func TestScanner_Scan(t *testing.T) {
 				"modules/vpc/main.tf",
 			},
 		},
+		{
+			name: "collapse terraform modules",
+			patterns: []config.Pattern{
+				{
+					Name:    "terraform",
+					Enabled: true,
+					Paths:   []string{"**/*.tf"},
+					Mode:    "terraform_module",
+				},
+			},
+			want: []string{
+				".",
+				"modules/vpc",
+			},
+		},
 		{
 			name: "disabled pattern",
 			patterns: []config.Pattern{
@@ -117,9 +132,66 @@ func TestScanner_Scan(t *testing.T) {
 				relPath, err := filepath.Rel(scanDir, f.Path)
 				require.NoError(t, err)
 				gotPaths = append(gotPaths, filepath.ToSlash(relPath))
+				assert.Equal(t, filepath.ToSlash(relPath), f.RelPath)
 			}
 
 			assert.ElementsMatch(t, tt.want, gotPaths)
 		})
 	}
 }
+
+func TestScanner_Scan_IceIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".iceignore":               "# Generated code\ngenerated/\n*.gen.tf\n",
+		"main.tf":                  "content",
+		"network.gen.tf":           "content",
+		"generated/vpc.tf":         "content",
+		"vendor/.iceignore":        "*.tf\n!keep.tf\n",
+		"vendor/module.tf":         "content",
+		"vendor/keep.tf":           "content",
+		"modules/vpc/main.tf":      "content",
+		"modules/vpc/other.gen.tf": "content",
+		"modules/vpc/variables.tf": "content",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	require.NoError(t, exec.Command("git", "init", tmpDir).Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "add", ".").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.email", "test@example.com").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.name", "Test User").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "commit", "-m", "Initial commit").Run())
+
+	scan := func(nested bool) ([]string, ScanStats) {
+		scanner := NewScanner(tmpDir, config.HistoryConfig{}, nested, zap.NewNop())
+		got, scanDir, err := scanner.Scan(tmpDir, []config.Pattern{
+			{Name: "terraform", Enabled: true, Paths: []string{"**/*.tf"}},
+		})
+		require.NoError(t, err)
+		defer os.RemoveAll(scanDir)
+
+		var gotPaths []string
+		for _, f := range got {
+			relPath, err := filepath.Rel(scanDir, f.Path)
+			require.NoError(t, err)
+			gotPaths = append(gotPaths, filepath.ToSlash(relPath))
+		}
+		return gotPaths, scanner.Stats()
+	}
+
+	// Only the root .iceignore is read by default
+	gotPaths, stats := scan(false)
+	assert.ElementsMatch(t, []string{"main.tf", "vendor/module.tf", "vendor/keep.tf", "modules/vpc/main.tf", "modules/vpc/variables.tf"}, gotPaths)
+	assert.Equal(t, 2, stats.IgnoredFiles)
+	assert.Equal(t, 1, stats.IgnoredDirs)
+	assert.Equal(t, []string{".iceignore"}, stats.IgnoreFiles)
+
+	gotPaths, stats = scan(true)
+	assert.ElementsMatch(t, []string{"main.tf", "vendor/keep.tf", "modules/vpc/main.tf", "modules/vpc/variables.tf"}, gotPaths)
+	assert.Equal(t, 3, stats.IgnoredFiles)
+	assert.Equal(t, 1, stats.IgnoredDirs)
+	assert.ElementsMatch(t, []string{".iceignore", "vendor/.iceignore"}, stats.IgnoreFiles)
+}
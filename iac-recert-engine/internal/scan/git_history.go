@@ -2,23 +2,138 @@ package scan
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/baldator/iac-recert-engine/internal/config"
 	"github.com/baldator/iac-recert-engine/internal/provider"
 	"github.com/baldator/iac-recert-engine/internal/types"
 	"go.uber.org/zap"
 )
 
+// RecertCommitMessage is the message of the commits ICE creates on recertification branches.
+// Once merged, these commits count as certifications rather than modifications.
+const RecertCommitMessage = "Trigger recertification"
+
+// defaultMaxCommits bounds how far back the history of a file is walked.
+const defaultMaxCommits = 50
+
+// Windows of the activity statistics recorded for risk scoring.
+const (
+	churnWindow  = 90 * 24 * time.Hour
+	authorWindow = 365 * 24 * time.Hour
+)
+
 type HistoryAnalyzer struct {
-	provider provider.GitProvider
-	logger   *zap.Logger
+	provider          provider.GitProvider
+	logger            *zap.Logger
+	localHistory      bool
+	ignoreCosmetic    bool
+	followRenames     bool
+	ignorePureRenames bool
+	maxCommits        int
+	exclusions        []commitExclusion
+	certification     []*regexp.Regexp
+	now               func() time.Time
+}
+
+// commitExclusion is the compiled form of config.CommitExclusion.
+type commitExclusion struct {
+	author  *regexp.Regexp
+	email   *regexp.Regexp
+	message *regexp.Regexp
+}
+
+// fileHistory is the outcome of walking back through the commits of a file.
+type fileHistory struct {
+	modified  types.Commit // Most recent commit that counts as a modification
+	certified types.Commit // Most recent recertification commit
+	found     bool
+	recent    map[string]bool // Hashes of modifications within the churn window
+	authors   map[string]bool // Authors of modifications within the author window
+}
+
+// merge adds the activity statistics of other, e.g. another member of a module.
+func (f *fileHistory) merge(other fileHistory) {
+	if f.recent == nil {
+		f.recent = make(map[string]bool)
+		f.authors = make(map[string]bool)
+	}
+	for hash := range other.recent {
+		f.recent[hash] = true
+	}
+	for author := range other.authors {
+		f.authors[author] = true
+	}
+}
+
+func NewHistoryAnalyzer(cfg config.HistoryConfig, provider provider.GitProvider, logger *zap.Logger) (*HistoryAnalyzer, error) {
+	// A followed move without content changes is not the last modification, unless
+	// ignore_pure_renames is turned off
+	ignorePureRenames := cfg.FollowRenames
+	if cfg.IgnorePureRenames != nil {
+		ignorePureRenames = *cfg.IgnorePureRenames
+	}
+	h := &HistoryAnalyzer{
+		provider:          provider,
+		logger:            logger,
+		localHistory:      cfg.Source == "local",
+		ignoreCosmetic:    cfg.IgnoreCosmeticChanges,
+		followRenames:     cfg.FollowRenames,
+		ignorePureRenames: ignorePureRenames,
+		maxCommits:        cfg.MaxCommits,
+		now:               time.Now,
+	}
+	if h.maxCommits == 0 {
+		h.maxCommits = defaultMaxCommits
+	}
+
+	for i, ex := range cfg.ExcludeCommits {
+		var compiled commitExclusion
+		var err error
+		if compiled.author, err = compileOptional(ex.Author); err != nil {
+			return nil, fmt.Errorf("invalid author regex in exclude_commits[%d]: %w", i, err)
+		}
+		if compiled.email, err = compileOptional(ex.Email); err != nil {
+			return nil, fmt.Errorf("invalid email regex in exclude_commits[%d]: %w", i, err)
+		}
+		if compiled.message, err = compileOptional(ex.Message); err != nil {
+			return nil, fmt.Errorf("invalid message regex in exclude_commits[%d]: %w", i, err)
+		}
+		if compiled.author == nil && compiled.email == nil && compiled.message == nil {
+			return nil, fmt.Errorf("exclude_commits[%d] must set at least one of author, email or message", i)
+		}
+		h.exclusions = append(h.exclusions, compiled)
+	}
+
+	certMessages := cfg.CertificationMessages
+	if len(certMessages) == 0 {
+		certMessages = []string{"^" + regexp.QuoteMeta(RecertCommitMessage)}
+	}
+	for _, m := range certMessages {
+		re, err := regexp.Compile(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certification message regex %q: %w", m, err)
+		}
+		h.certification = append(h.certification, re)
+	}
+
+	return h, nil
 }
 
-func NewHistoryAnalyzer(provider provider.GitProvider, logger *zap.Logger) *HistoryAnalyzer {
-	return &HistoryAnalyzer{
-		provider: provider,
-		logger:   logger,
+// SetClock sets the time the churn and author windows end at, e.g. the forecast date.
+func (h *HistoryAnalyzer) SetClock(now func() time.Time) {
+	h.now = now
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
 	}
+	return regexp.Compile(expr)
 }
 
 func (h *HistoryAnalyzer) Enrich(ctx context.Context, files []types.FileInfo, repoRoot string) ([]types.FileInfo, error) {
@@ -27,35 +142,32 @@ func (h *HistoryAnalyzer) Enrich(ctx context.Context, files []types.FileInfo, re
 	h.logger.Debug("starting history analysis", zap.Int("files", len(files)), zap.String("repo_root", repoRoot))
 
 	for i, file := range files {
-		// Calculate relative path from repo root if needed.
-		// We assume repoRoot is the local path where we scanned.
-		relPath, err := filepath.Rel(repoRoot, file.Path)
-		if err != nil {
-			h.logger.Warn("failed to get relative path", zap.String("file", file.Path), zap.Error(err))
-			relPath = file.Path
-		}
-		// Normalize path separators to forward slashes for git APIs
-		relPath = filepath.ToSlash(relPath)
+		relPath := h.relPath(repoRoot, file.Path)
 
 		h.logger.Debug("analyzing file history", zap.Int("index", i+1), zap.String("file", relPath))
 
-		ts, commit, err := h.provider.GetLastModificationDate(ctx, relPath)
-		if err != nil {
-			h.logger.Warn("failed to get last modification date", zap.String("file", relPath), zap.Error(err))
-			// We still include the file but with zero time, which will likely trigger recertification or error handling downstream
-		} else {
-			file.LastModified = ts
+		history := h.lastChange(ctx, file, repoRoot)
+		// A certification counts even when the last modification is unknown
+		file.LastCertified = history.certified.Timestamp
+		if history.found {
+			commit := history.modified
+			file.LastModified = commit.Timestamp
 			file.CommitHash = commit.Hash
 			file.CommitAuthor = commit.Author
 			file.CommitEmail = commit.Email
 			file.CommitMsg = commit.Message
+			file.RecentCommits = len(history.recent)
+			file.RecentAuthors = len(history.authors)
 
 			h.logger.Debug("file history retrieved",
 				zap.String("file", relPath),
-				zap.Time("last_modified", ts),
+				zap.String("resource", file.Resource),
+				zap.Time("last_modified", file.LastModified),
+				zap.Time("last_certified", file.LastCertified),
 				zap.String("commit_hash", commit.Hash),
 				zap.String("author", commit.Author))
 		}
+		// Otherwise we still include the file but with zero time, which will likely trigger recertification or error handling downstream
 
 		enriched = append(enriched, file)
 	}
@@ -63,3 +175,288 @@ func (h *HistoryAnalyzer) Enrich(ctx context.Context, files []types.FileInfo, re
 	h.logger.Debug("history analysis completed", zap.Int("enriched_files", len(enriched)))
 	return enriched, nil
 }
+
+// lastChange returns the most recent qualifying modification and certification of the unit
+// described by file. Kubernetes resources are dated from the local line history of their range,
+// multi-file units (e.g. Terraform modules) take the most recent change of any member, and plain
+// files use the configured history source.
+func (h *HistoryAnalyzer) lastChange(ctx context.Context, file types.FileInfo, repoRoot string) fileHistory {
+	git := localGit{dir: repoRoot}
+	if file.Resource != "" {
+		relPath := h.relPath(repoRoot, file.Path)
+		commits, err := git.lineRangeCommits(ctx, relPath, file.StartLine, file.EndLine, h.maxCommits)
+		if err != nil {
+			h.logger.Warn("failed to get history of resource", zap.String("file", relPath), zap.String("resource", file.Resource), zap.Error(err))
+			return fileHistory{}
+		}
+		return h.walk(ctx, git, relPath, file.Resource, commits)
+	}
+
+	paths := []string{file.Path}
+	if len(file.Members) > 0 {
+		paths = file.Members
+	}
+
+	var result fileHistory
+	for _, p := range paths {
+		relPath := h.relPath(repoRoot, p)
+		var commits []types.Commit
+		var err error
+		if h.localHistory {
+			commits, err = git.fileCommits(ctx, relPath, h.maxCommits, h.followRenames)
+		} else {
+			commits, err = h.providerCommits(ctx, relPath)
+		}
+		if err != nil {
+			h.logger.Warn("failed to get last modification date", zap.String("file", relPath), zap.Error(err))
+			continue
+		}
+		if h.ignorePureRenames && !h.followRenames {
+			h.markMove(ctx, git, relPath, commits)
+		}
+		member := h.walk(ctx, git, relPath, "", commits)
+		if member.certified.Timestamp.After(result.certified.Timestamp) {
+			result.certified = member.certified
+		}
+		if !member.found {
+			continue
+		}
+		result.merge(member)
+		if !result.found || member.modified.Timestamp.After(result.modified.Timestamp) {
+			result.modified = member.modified
+			result.found = true
+		}
+	}
+	return result
+}
+
+// markMove records whether the oldest commit of a history that isn't followed across renames
+// moved the file there, so that a pure move can be skipped. Only a complete history starts with
+// the commit that added the file under its current path.
+func (h *HistoryAnalyzer) markMove(ctx context.Context, git localGit, relPath string, commits []types.Commit) {
+	if len(commits) == 0 || len(commits) >= h.maxCommits {
+		return
+	}
+	oldest := &commits[len(commits)-1]
+	var previous string
+	var pure bool
+	var err error
+	if h.localHistory {
+		previous, pure, err = git.commitRename(ctx, oldest.Hash, relPath)
+	} else {
+		previous, pure, err = h.provider.GetCommitRename(ctx, oldest.Hash, relPath)
+	}
+	if err != nil {
+		h.logger.Warn("failed to check commit for rename", zap.String("file", relPath), zap.String("commit_hash", oldest.Hash), zap.Error(err))
+		return
+	}
+	oldest.PreviousPath = previous
+	oldest.PureRename = pure
+}
+
+// providerCommits lists the commits of a file through the provider. Path-filtered commit lists
+// stop at the commit that moved the file there, so when following renames and the whole history
+// fits within the limit, the oldest commit is checked for a rename and the listing continues
+// under the previous path.
+func (h *HistoryAnalyzer) providerCommits(ctx context.Context, relPath string) ([]types.Commit, error) {
+	commits, err := h.provider.ListFileCommits(ctx, relPath, h.maxCommits)
+	if err != nil || !h.followRenames {
+		return commits, err
+	}
+
+	path := relPath
+	for i := range commits {
+		commits[i].Path = path
+	}
+	checked := make(map[string]bool)
+	for len(commits) > 0 && len(commits) < h.maxCommits {
+		oldest := &commits[len(commits)-1]
+		if checked[oldest.Hash] {
+			break
+		}
+		checked[oldest.Hash] = true
+
+		previous, pure, err := h.provider.GetCommitRename(ctx, oldest.Hash, path)
+		if err != nil {
+			h.logger.Warn("failed to check commit for rename", zap.String("file", path), zap.String("commit_hash", oldest.Hash), zap.Error(err))
+			break
+		}
+		if previous == "" {
+			break
+		}
+		oldest.PreviousPath = previous
+		oldest.PureRename = pure
+		h.logger.Debug("following rename", zap.String("file", path), zap.String("previous_path", previous), zap.String("commit_hash", oldest.Hash))
+
+		// The renaming commit also touches the previous path, so ask for one extra commit
+		older, err := h.provider.ListFileCommits(ctx, previous, h.maxCommits-len(commits)+1)
+		if err != nil {
+			h.logger.Warn("failed to get history of previous path", zap.String("file", previous), zap.Error(err))
+			break
+		}
+		path = previous
+		for _, c := range older {
+			if c.Hash == oldest.Hash {
+				continue
+			}
+			c.Path = path
+			commits = append(commits, c)
+		}
+	}
+
+	if len(commits) > h.maxCommits {
+		commits = commits[:h.maxCommits]
+	}
+	return commits, nil
+}
+
+// walk goes back through commits (newest first) and returns the most recent one that counts
+// as a modification. Recertification commits are recorded as certifications, and commits
+// matching an exclusion rule, pure renames of followed files or (optionally) commits making only
+// cosmetic changes are skipped. If every commit is skipped, the oldest one is used so the file is
+// not treated as never modified, unless the history was cut at max_commits or at a rename that
+// isn't followed: the real last modification is then older than any commit seen, so the
+// modification date is left unknown.
+func (h *HistoryAnalyzer) walk(ctx context.Context, git localGit, relPath, resource string, commits []types.Commit) fileHistory {
+	result := fileHistory{
+		recent:  make(map[string]bool),
+		authors: make(map[string]bool),
+	}
+
+	// Activity statistics cover every commit that isn't a certification or excluded
+	now := h.now()
+	for _, c := range commits {
+		if h.isCertification(c) || h.isExcluded(c) {
+			continue
+		}
+		age := now.Sub(c.Timestamp)
+		if age <= churnWindow {
+			result.recent[c.Hash] = true
+		}
+		if age <= authorWindow {
+			author := strings.ToLower(c.Email)
+			if author == "" {
+				author = c.Author
+			}
+			result.authors[author] = true
+		}
+	}
+
+	for _, c := range commits {
+		if h.isCertification(c) {
+			if result.certified.Timestamp.IsZero() {
+				result.certified = c
+			}
+			h.logger.Debug("counting commit as certification", zap.String("file", relPath), zap.String("commit_hash", c.Hash))
+			continue
+		}
+		if h.isExcluded(c) {
+			h.logger.Debug("skipping excluded commit", zap.String("file", relPath), zap.String("commit_hash", c.Hash), zap.String("author", c.Author))
+			continue
+		}
+		if h.ignorePureRenames && c.PureRename {
+			h.logger.Debug("skipping pure rename", zap.String("file", relPath), zap.String("previous_path", c.PreviousPath), zap.String("commit_hash", c.Hash))
+			continue
+		}
+		if h.ignoreCosmetic && h.isCosmetic(ctx, git, relPath, resource, c) {
+			h.logger.Debug("skipping cosmetic commit", zap.String("file", relPath), zap.String("resource", resource), zap.String("commit_hash", c.Hash))
+			continue
+		}
+		result.modified = c
+		result.found = true
+		return result
+	}
+
+	if len(commits) >= h.maxCommits {
+		h.logger.Warn("no qualifying modification found within max_commits, modification date unknown",
+			zap.String("file", relPath),
+			zap.Int("max_commits", h.maxCommits))
+		return result
+	}
+	if len(commits) > 0 && commits[len(commits)-1].PreviousPath != "" {
+		h.logger.Warn("no qualifying modification found since the file was moved, modification date unknown",
+			zap.String("file", relPath),
+			zap.String("previous_path", commits[len(commits)-1].PreviousPath))
+		return result
+	}
+	if len(commits) > 0 {
+		oldest := commits[len(commits)-1]
+		h.logger.Warn("no qualifying modification found, using oldest known commit",
+			zap.String("file", relPath),
+			zap.Int("commits", len(commits)),
+			zap.String("commit_hash", oldest.Hash))
+		result.modified = oldest
+		result.found = true
+	}
+	return result
+}
+
+func (h *HistoryAnalyzer) isCertification(c types.Commit) bool {
+	for _, re := range h.certification {
+		if re.MatchString(c.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *HistoryAnalyzer) isExcluded(c types.Commit) bool {
+	for _, ex := range h.exclusions {
+		if ex.author != nil && !ex.author.MatchString(c.Author) {
+			continue
+		}
+		if ex.email != nil && !ex.email.MatchString(c.Email) {
+			continue
+		}
+		if ex.message != nil && !ex.message.MatchString(c.Message) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isCosmetic reports whether a commit only changed whitespace or comments of the file (or of
+// the Kubernetes resource within it). Commits that cannot be compared, such as the one adding
+// the file, are treated as meaningful. When following renames, each side is read from the path
+// the file had at that point.
+func (h *HistoryAnalyzer) isCosmetic(ctx context.Context, git localGit, relPath, resource string, c types.Commit) bool {
+	afterPath, beforePath := relPath, relPath
+	if c.Path != "" {
+		afterPath, beforePath = c.Path, c.Path
+	}
+	if c.PreviousPath != "" {
+		beforePath = c.PreviousPath
+	}
+
+	after, err := git.show(ctx, c.Hash, afterPath)
+	if err != nil {
+		h.logger.Debug("cannot compare commit content", zap.String("file", afterPath), zap.String("commit_hash", c.Hash), zap.Error(err))
+		return false
+	}
+	before, err := git.show(ctx, c.Hash+"^", beforePath)
+	if err != nil {
+		return false
+	}
+
+	if resource != "" {
+		afterDoc, okAfter := findK8sResource(after, resource)
+		beforeDoc, okBefore := findK8sResource(before, resource)
+		if !okAfter || !okBefore {
+			return false
+		}
+		after, before = []byte(afterDoc), []byte(beforeDoc)
+	}
+
+	return semanticallyEqual(relPath, before, after)
+}
+
+// relPath returns the path relative to the repo root, normalized to forward slashes for git APIs.
+func (h *HistoryAnalyzer) relPath(repoRoot, path string) string {
+	relPath, err := filepath.Rel(repoRoot, path)
+	if err != nil {
+		h.logger.Warn("failed to get relative path", zap.String("file", path), zap.Error(err))
+		relPath = path
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,257 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/provider"
+	"github.com/baldator/iac-recert-engine/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// commitFile writes content to a file in the repository and commits it with the given date.
+func commitFile(t *testing.T, dir, name, content, message, date string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, exec.Command("git", "-C", dir, "add", ".").Run())
+	cmd := exec.Command("git", "-C", dir, "commit", "-m", message)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	require.NoError(t, cmd.Run())
+}
+
+func TestHistoryAnalyzer_Enrich_KubernetesResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", tmpDir).Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.email", "test@example.com").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.name", "Test User").Run())
+
+	deployment := "kind: Deployment\nmetadata:\n  name: web\n"
+	commitFile(t, tmpDir, "app.yaml", deployment+"---\nkind: Service\nmetadata:\n  name: web\n", "Initial commit", "2024-01-01T00:00:00Z")
+	commitFile(t, tmpDir, "app.yaml", deployment+"---\nkind: Service\nmetadata:\n  name: web-svc\n", "Rename service", "2024-06-01T00:00:00Z")
+
+	files := []types.FileInfo{
+		{Path: filepath.Join(tmpDir, "app.yaml"), Resource: "Deployment/web", StartLine: 1, EndLine: 3},
+		{Path: filepath.Join(tmpDir, "app.yaml"), Resource: "Service/web-svc", StartLine: 5, EndLine: 7},
+	}
+
+	analyzer, err := NewHistoryAnalyzer(config.HistoryConfig{}, nil, zap.NewNop())
+	require.NoError(t, err)
+	enriched, err := analyzer.Enrich(context.Background(), files, tmpDir)
+	require.NoError(t, err)
+	require.Len(t, enriched, 2)
+
+	assert.Equal(t, "2024-01-01", enriched[0].LastModified.UTC().Format("2006-01-02"))
+	assert.Equal(t, "Initial commit", enriched[0].CommitMsg)
+	assert.Equal(t, "2024-06-01", enriched[1].LastModified.UTC().Format("2006-01-02"))
+	assert.Equal(t, "Rename service", enriched[1].CommitMsg)
+}
+
+func TestHistoryAnalyzer_Walk(t *testing.T) {
+	cfg := config.HistoryConfig{
+		ExcludeCommits: []config.CommitExclusion{
+			{Author: `^renovate\[bot\]$`},
+			{Email: `^ci@example\.com$`, Message: `^terraform fmt`},
+		},
+	}
+	analyzer, err := NewHistoryAnalyzer(cfg, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	commits := []types.Commit{
+		{Hash: "a", Author: "renovate[bot]", Message: "Update provider", Timestamp: day(5)},
+		{Hash: "b", Author: "ICE", Message: RecertCommitMessage, Timestamp: day(4)},
+		{Hash: "c", Author: "CI", Email: "ci@example.com", Message: "terraform fmt", Timestamp: day(3)},
+		{Hash: "d", Author: "Jane Doe", Email: "jane@example.com", Message: "Open port 443", Timestamp: day(2)},
+		{Hash: "e", Author: "John Doe", Email: "john@example.com", Message: "Initial", Timestamp: day(1)},
+	}
+
+	history := analyzer.walk(context.Background(), localGit{}, "main.tf", "", commits)
+	require.True(t, history.found)
+	assert.Equal(t, "d", history.modified.Hash)
+	assert.Equal(t, "b", history.certified.Hash)
+
+	t.Run("activity statistics", func(t *testing.T) {
+		now := time.Now()
+		recent := []types.Commit{
+			{Hash: "a", Author: "Jane Doe", Email: "Jane@example.com", Message: "Tighten policy", Timestamp: now.AddDate(0, 0, -10)},
+			{Hash: "b", Author: "renovate[bot]", Message: "Update provider", Timestamp: now.AddDate(0, 0, -20)},
+			{Hash: "c", Author: "Jane", Email: "jane@example.com", Message: "Add policy", Timestamp: now.AddDate(0, 0, -120)},
+			{Hash: "d", Author: "John Doe", Email: "john@example.com", Message: "Initial", Timestamp: now.AddDate(-2, 0, 0)},
+		}
+		history := analyzer.walk(context.Background(), localGit{}, "main.tf", "", recent)
+		assert.Len(t, history.recent, 1)
+		assert.Len(t, history.authors, 1)
+
+		// As of a forecast date 100 days out, the commits have left the churn window
+		forecast, err := NewHistoryAnalyzer(cfg, nil, zap.NewNop())
+		require.NoError(t, err)
+		forecast.SetClock(func() time.Time { return now.AddDate(0, 0, 100) })
+		history = forecast.walk(context.Background(), localGit{}, "main.tf", "", recent)
+		assert.Empty(t, history.recent)
+		assert.Len(t, history.authors, 1)
+	})
+
+	t.Run("falls back to oldest commit", func(t *testing.T) {
+		history := analyzer.walk(context.Background(), localGit{}, "main.tf", "", commits[:1])
+		require.True(t, history.found)
+		assert.Equal(t, "a", history.modified.Hash)
+	})
+
+	t.Run("truncated history is unknown", func(t *testing.T) {
+		truncated, err := NewHistoryAnalyzer(config.HistoryConfig{MaxCommits: 3, ExcludeCommits: cfg.ExcludeCommits}, nil, zap.NewNop())
+		require.NoError(t, err)
+		// Every commit within max_commits is skipped, so the last modification is older than
+		// the oldest commit seen; the certification still counts
+		history := truncated.walk(context.Background(), localGit{}, "main.tf", "", commits[:3])
+		assert.False(t, history.found)
+		assert.Equal(t, "b", history.certified.Hash)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		_, err := NewHistoryAnalyzer(config.HistoryConfig{ExcludeCommits: []config.CommitExclusion{{}}}, nil, zap.NewNop())
+		assert.Error(t, err)
+	})
+}
+
+func TestHistoryAnalyzer_Enrich_IgnoreCosmeticChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", tmpDir).Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.email", "test@example.com").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.name", "Test User").Run())
+
+	commitFile(t, tmpDir, "main.tf", "bucket = \"logs\"\n", "Add bucket", "2024-01-01T00:00:00Z")
+	commitFile(t, tmpDir, "main.tf", "# Logs\nbucket    = \"logs\"\n", "Reformat", "2024-06-01T00:00:00Z")
+
+	files := []types.FileInfo{{Path: filepath.Join(tmpDir, "main.tf")}}
+
+	analyzer, err := NewHistoryAnalyzer(config.HistoryConfig{Source: "local", IgnoreCosmeticChanges: true}, nil, zap.NewNop())
+	require.NoError(t, err)
+	enriched, err := analyzer.Enrich(context.Background(), files, tmpDir)
+	require.NoError(t, err)
+	require.Len(t, enriched, 1)
+	assert.Equal(t, "Add bucket", enriched[0].CommitMsg)
+
+	analyzer, err = NewHistoryAnalyzer(config.HistoryConfig{Source: "local"}, nil, zap.NewNop())
+	require.NoError(t, err)
+	enriched, err = analyzer.Enrich(context.Background(), files, tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "Reformat", enriched[0].CommitMsg)
+}
+
+func TestHistoryAnalyzer_Enrich_FollowRenames(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", tmpDir).Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.email", "test@example.com").Run())
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "config", "user.name", "Test User").Run())
+
+	content := "resource \"aws_vpc\" \"main\" {\n  cidr_block = \"10.0.0.0/16\"\n}\n"
+	commitFile(t, tmpDir, "prod/vpc.tf", content, "Add VPC", "2024-01-01T00:00:00Z")
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "prod", "network"), 0755))
+	require.NoError(t, exec.Command("git", "-C", tmpDir, "mv", "prod/vpc.tf", "prod/network/vpc.tf").Run())
+	cmd := exec.Command("git", "-C", tmpDir, "commit", "-m", "Move VPC")
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2024-06-01T00:00:00Z", "GIT_COMMITTER_DATE=2024-06-01T00:00:00Z")
+	require.NoError(t, cmd.Run())
+
+	files := []types.FileInfo{{Path: filepath.Join(tmpDir, "prod", "network", "vpc.tf")}}
+
+	for _, tc := range renameCombinations {
+		cfg := config.HistoryConfig{Source: "local", FollowRenames: tc.follow, IgnorePureRenames: tc.ignore}
+		analyzer, err := NewHistoryAnalyzer(cfg, nil, zap.NewNop())
+		require.NoError(t, err)
+		enriched, err := analyzer.Enrich(context.Background(), files, tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, enriched[0].CommitMsg, tc.name)
+	}
+
+	git := localGit{dir: tmpDir}
+	commits, err := git.fileCommits(context.Background(), "prod/network/vpc.tf", 10, true)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "prod/vpc.tf", commits[0].PreviousPath)
+	assert.True(t, commits[0].PureRename)
+	assert.Equal(t, "prod/vpc.tf", commits[1].Path)
+}
+
+// renamingProvider serves path-filtered commit lists and renames from memory.
+type renamingProvider struct {
+	provider.GitProvider
+	commits map[string][]types.Commit
+	renames map[string]string // commit hash -> previous path
+}
+
+func (p *renamingProvider) ListFileCommits(ctx context.Context, filePath string, limit int) ([]types.Commit, error) {
+	commits := p.commits[filePath]
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return append([]types.Commit(nil), commits...), nil
+}
+
+func (p *renamingProvider) GetCommitRename(ctx context.Context, hash, filePath string) (string, bool, error) {
+	previous, ok := p.renames[hash]
+	return previous, ok, nil
+}
+
+func TestHistoryAnalyzer_ProviderCommits_FollowRenames(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	move := types.Commit{Hash: "c", Message: "Move VPC", Timestamp: day(3)}
+	prov := &renamingProvider{
+		commits: map[string][]types.Commit{
+			"prod/network/vpc.tf": {move},
+			"prod/vpc.tf": {
+				move,
+				{Hash: "a", Message: "Add VPC", Timestamp: day(1)},
+			},
+		},
+		renames: map[string]string{"c": "prod/vpc.tf"},
+	}
+
+	analyzer, err := NewHistoryAnalyzer(config.HistoryConfig{FollowRenames: true}, prov, zap.NewNop())
+	require.NoError(t, err)
+	commits, err := analyzer.providerCommits(context.Background(), "prod/network/vpc.tf")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "prod/vpc.tf", commits[0].PreviousPath)
+	assert.Equal(t, "prod/vpc.tf", commits[1].Path)
+
+	for _, tc := range renameCombinations {
+		analyzer, err := NewHistoryAnalyzer(config.HistoryConfig{FollowRenames: tc.follow, IgnorePureRenames: tc.ignore}, prov, zap.NewNop())
+		require.NoError(t, err)
+		history := analyzer.lastChange(context.Background(), types.FileInfo{Path: "/repo/prod/network/vpc.tf"}, "/repo")
+		var got string
+		if history.found {
+			got = history.modified.Message
+		}
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+// renameCombinations are the outcomes of follow_renames and ignore_pure_renames for a file that
+// was added, then moved without content changes ("Move VPC").
+var renameCombinations = []struct {
+	name   string
+	follow bool
+	ignore *bool
+	want   string // Last modification, empty when unknown
+}{
+	{name: "neither", want: "Move VPC"},
+	{name: "follow", follow: true, want: "Add VPC"},
+	{name: "follow counting pure renames", follow: true, ignore: boolPtr(false), want: "Move VPC"},
+	{name: "follow ignoring pure renames", follow: true, ignore: boolPtr(true), want: "Add VPC"},
+	// Without following, the history before the move is not known
+	{name: "ignore only", ignore: boolPtr(true), want: ""},
+	{name: "counting pure renames", ignore: boolPtr(false), want: "Move VPC"},
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
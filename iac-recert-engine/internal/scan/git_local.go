@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baldator/iac-recert-engine/internal/types"
+)
+
+// gitLogFormat separates commits with a record separator and fields with a unit separator,
+// so multi-line commit messages can be parsed safely. The trailing separator sets apart the
+// --name-status output git appends after the message.
+const gitLogFormat = "--format=%x1e%H%x1f%an%x1f%ae%x1f%cI%x1f%B%x1f"
+
+// localGit runs git commands against the local clone created by the Scanner.
+type localGit struct {
+	dir string
+}
+
+// log runs git log with the given arguments and returns the commits, newest first.
+// Callers pass either --no-patch or --name-status to control the diff output.
+func (g localGit) log(ctx context.Context, args ...string) ([]types.Commit, error) {
+	cmdArgs := append([]string{"-C", g.dir, "log", gitLogFormat}, args...)
+	cmd := exec.CommandContext(ctx, "git", cmdArgs...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git log failed: %w, output: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var commits []types.Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log output: %q", record)
+		}
+		ts, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit date %q: %w", fields[3], err)
+		}
+		commit := types.Commit{
+			Hash:      fields[0],
+			Author:    fields[1],
+			Email:     fields[2],
+			Message:   strings.TrimSpace(fields[4]),
+			Timestamp: ts,
+		}
+		parseNameStatus(&commit, fields[5])
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// parseNameStatus records the path of the file and any rename from the --name-status output
+// of a commit, e.g. "M\tmain.tf" or "R100\told.tf\tnew.tf". Similarity 100 is a pure rename.
+func parseNameStatus(commit *types.Commit, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "\t")
+		if len(parts) < 2 {
+			continue
+		}
+		commit.Path = parts[len(parts)-1]
+		if strings.HasPrefix(parts[0], "R") && len(parts) == 3 {
+			commit.PreviousPath = parts[1]
+			commit.PureRename = parts[0] == "R100"
+		}
+	}
+}
+
+// lineRangeCommits returns up to limit commits that touched the given line range of a file, newest first.
+func (g localGit) lineRangeCommits(ctx context.Context, relPath string, startLine, endLine, limit int) ([]types.Commit, error) {
+	commits, err := g.log(ctx, "--no-patch", "-n", strconv.Itoa(limit), "-L", fmt.Sprintf("%d,%d:%s", startLine, endLine, relPath))
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found for lines %d-%d of file: %s", startLine, endLine, relPath)
+	}
+	return commits, nil
+}
+
+// fileCommits returns up to limit commits that touched a file, newest first. When follow is
+// set, the history continues across renames and each commit records the path of the file.
+func (g localGit) fileCommits(ctx context.Context, relPath string, limit int, follow bool) ([]types.Commit, error) {
+	if follow {
+		return g.log(ctx, "--follow", "-M", "--name-status", "-n", strconv.Itoa(limit), "--", relPath)
+	}
+	return g.log(ctx, "--no-patch", "-n", strconv.Itoa(limit), "--", relPath)
+}
+
+// commitRename returns the previous path of a file the commit renamed, and whether the rename
+// left the content unchanged. The previous path is empty if the commit didn't rename the file.
+func (g localGit) commitRename(ctx context.Context, hash, relPath string) (string, bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", g.dir, "show", "--name-status", "-M", "--format=", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("git show %s failed: %w", hash, err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		var commit types.Commit
+		parseNameStatus(&commit, line)
+		if commit.PreviousPath != "" && commit.Path == relPath {
+			return commit.PreviousPath, commit.PureRename, nil
+		}
+	}
+	return "", false, nil
+}
+
+// show returns the content of a file at the given revision.
+func (g localGit) show(ctx context.Context, rev, relPath string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", g.dir, "show", rev+":"+relPath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show %s:%s failed: %w", rev, relPath, err)
+	}
+	return output, nil
+}
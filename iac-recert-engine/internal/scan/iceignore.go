@@ -0,0 +1,133 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFileName is the name of the gitignore-syntax file repo owners use to exclude paths from
+// recertification. It is read from the repository root and, optionally, from nested directories.
+const IgnoreFileName = ".iceignore"
+
+// ignoreRule is a single pattern line of an .iceignore file.
+type ignoreRule struct {
+	base    string // Directory of the .iceignore file relative to the repo root, "" for the root
+	pattern string // doublestar pattern matched against paths relative to base
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher applies the rules of every .iceignore file loaded so far. As in gitignore,
+// the last matching rule wins and rules from deeper files are loaded after their parents.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// load reads the .iceignore file of dir (relative to root, "" for the root), if there is one.
+func (m *ignoreMatcher) load(root, dir string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	rules, err := parseIgnoreRules(content, dir)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", path.Join(dir, IgnoreFileName), err)
+	}
+	m.rules = append(m.rules, rules...)
+	return true, nil
+}
+
+// parseIgnoreRules parses gitignore syntax: blank lines and # comments are skipped, a leading !
+// re-includes, a trailing / matches directories only, and patterns containing a / are anchored
+// to the file's directory while others match a name at any depth.
+func parseIgnoreRules(content []byte, base string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		if !doublestar.ValidatePattern(line) {
+			return nil, fmt.Errorf("line %d: invalid pattern %q", lineNo, scanner.Text())
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// ignored reports whether a file (relative to the repo root, forward slashes) is excluded,
+// either directly or because one of its parent directories is.
+func (m *ignoreMatcher) ignored(relPath string) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(relPath, false)
+}
+
+// ignoredDir reports whether a directory (relative to the repo root, forward slashes) is
+// excluded. Its parents are not checked, as the scanner skips ignored directories.
+func (m *ignoreMatcher) ignoredDir(relPath string) bool {
+	return len(m.rules) > 0 && m.match(relPath, true)
+}
+
+// match applies the rules to a single path without considering its parents.
+func (m *ignoreMatcher) match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel := relPath
+		if r.base != "" {
+			if !strings.HasPrefix(relPath, r.base+"/") {
+				continue
+			}
+			rel = relPath[len(r.base)+1:]
+		}
+		if ok, _ := doublestar.Match(r.pattern, rel); ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,52 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root, err := parseIgnoreRules([]byte("# comment\n\n/build\n*.gen.tf\ncache/\n!important.gen.tf\ndocs/**/*.tf\n\\#notes.tf\n"), "")
+	require.NoError(t, err)
+	nested, err := parseIgnoreRules([]byte("*.tf\n"), "vendor")
+	require.NoError(t, err)
+	m := &ignoreMatcher{rules: append(root, nested...)}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"build/main.tf", true},
+		{"modules/build/main.tf", false},
+		{"network.gen.tf", true},
+		{"modules/vpc/network.gen.tf", true},
+		{"modules/important.gen.tf", false},
+		{"cache/main.tf", true},
+		{"modules/cache/main.tf", true},
+		{"cache.tf", false},
+		{"docs/examples/a/main.tf", true},
+		{"#notes.tf", true},
+		{"vendor/aws/main.tf", true},
+		{"other/vendor.tf", false},
+		{"main.tf", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, m.ignored(tt.path))
+		})
+	}
+
+	t.Run("directories", func(t *testing.T) {
+		assert.True(t, m.ignoredDir("cache"))
+		assert.True(t, m.ignoredDir("modules/cache"))
+		assert.True(t, m.ignoredDir("build"))
+		assert.False(t, m.ignoredDir("modules"))
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := parseIgnoreRules([]byte("[unclosed\n"), "")
+		assert.Error(t, err)
+	})
+}
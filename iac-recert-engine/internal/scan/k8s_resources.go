@@ -0,0 +1,94 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// k8sResource is a single Kubernetes object within a (possibly multi-document) YAML manifest.
+type k8sResource struct {
+	ID        string // kind/namespace/name, or kind/name for objects without a namespace
+	StartLine int    // 1-based, inclusive
+	EndLine   int    // 1-based, inclusive
+	Content   string
+}
+
+// splitK8sResources splits a multi-document YAML manifest into its Kubernetes objects,
+// recording the line range each object occupies. Documents without a kind are ignored.
+func splitK8sResources(content []byte) ([]k8sResource, error) {
+	lines := strings.Split(string(content), "\n")
+
+	var resources []k8sResource
+	start := 0
+	flush := func(end int) error {
+		first, last := start, end
+		// Trim surrounding blank lines so the range only covers the object itself
+		for first < last && strings.TrimSpace(lines[first]) == "" {
+			first++
+		}
+		for last > first && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+		if first == last {
+			return nil
+		}
+
+		doc := strings.Join(lines[first:last], "\n")
+		var meta struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+			return fmt.Errorf("failed to parse document at line %d: %w", first+1, err)
+		}
+		if meta.Kind == "" {
+			return nil
+		}
+
+		id := meta.Kind + "/" + meta.Metadata.Name
+		if meta.Metadata.Namespace != "" {
+			id = meta.Kind + "/" + meta.Metadata.Namespace + "/" + meta.Metadata.Name
+		}
+		resources = append(resources, k8sResource{
+			ID:        id,
+			StartLine: first + 1,
+			EndLine:   last,
+			Content:   doc,
+		})
+		return nil
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") || trimmed == "..." {
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			start = i + 1
+		}
+	}
+	if err := flush(len(lines)); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// findK8sResource returns the content of the object with the given ID within a manifest.
+func findK8sResource(content []byte, id string) (string, bool) {
+	resources, err := splitK8sResources(content)
+	if err != nil {
+		return "", false
+	}
+	for _, r := range resources {
+		if r.ID == id {
+			return r.Content, true
+		}
+	}
+	return "", false
+}
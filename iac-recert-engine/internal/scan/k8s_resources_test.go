@@ -0,0 +1,53 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitK8sResources(t *testing.T) {
+	manifest := `# Application bundle
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+
+---
+# empty document
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: web-reader
+`
+
+	resources, err := splitK8sResources([]byte(manifest))
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Deployment/shop/web", resources[0].ID)
+	assert.Equal(t, 1, resources[0].StartLine)
+	assert.Equal(t, 6, resources[0].EndLine)
+
+	assert.Equal(t, "Service/shop/web", resources[1].ID)
+	assert.Equal(t, 8, resources[1].StartLine)
+	assert.Equal(t, 12, resources[1].EndLine)
+
+	assert.Equal(t, "ClusterRole/web-reader", resources[2].ID)
+	assert.Equal(t, 17, resources[2].StartLine)
+	assert.Equal(t, 20, resources[2].EndLine)
+}
+
+func TestSplitK8sResources_InvalidYAML(t *testing.T) {
+	_, err := splitK8sResources([]byte("kind: [unterminated\n"))
+	assert.Error(t, err)
+}
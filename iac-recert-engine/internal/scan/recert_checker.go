@@ -2,19 +2,55 @@ package scan
 
 import (
 	"path/filepath"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/risk"
 	"github.com/baldator/iac-recert-engine/internal/types"
 	"go.uber.org/zap"
 )
 
+// defaultPriorityBuckets are used when neither the config nor the pattern defines buckets.
+var defaultPriorityBuckets = []config.PriorityBucket{
+	{Name: "Critical", MinRatio: 1.5},
+	{Name: "High", MinRatio: 1.0},
+	{Name: "Medium", MinRatio: 0.8},
+	{Name: "Low", MinRatio: 0},
+}
+
 type Checker struct {
-	logger *zap.Logger
+	logger     *zap.Logger
+	priorities []config.PriorityBucket
+	scorer     risk.Scorer
+	exemptions []types.Exemption
+	now        func() time.Time
+}
+
+// NewChecker creates a Checker. Without a scorer, priorities come from the overdue ratio.
+func NewChecker(priorities []config.PriorityBucket, scorer risk.Scorer, logger *zap.Logger) *Checker {
+	if len(priorities) == 0 {
+		priorities = defaultPriorityBuckets
+	}
+	return &Checker{logger: logger, priorities: sortBuckets(priorities), scorer: scorer, now: time.Now}
+}
+
+// Priorities returns the global priority buckets in descending MinRatio order, i.e. from the
+// most to the least urgent.
+func (c *Checker) Priorities() []config.PriorityBucket {
+	return c.priorities
+}
+
+// SetClock sets the time results are evaluated against, e.g. a future date for forecasts.
+func (c *Checker) SetClock(now func() time.Time) {
+	c.now = now
 }
 
-func NewChecker(logger *zap.Logger) *Checker {
-	return &Checker{logger: logger}
+// SetExemptions sets the exemptions applied by subsequent checks. In-repo exemptions are only
+// known once the repository is cloned, so they are not passed to NewChecker.
+func (c *Checker) SetExemptions(exemptions []types.Exemption) {
+	c.exemptions = exemptions
 }
 
 func (c *Checker) Check(files []types.FileInfo, patterns []config.Pattern, repoRoot string) ([]types.RecertCheckResult, error) {
@@ -23,6 +59,9 @@ func (c *Checker) Check(files []types.FileInfo, patterns []config.Pattern, repoR
 	c.logger.Info("checking files for recertification", zap.Int("count", len(files)))
 	c.logger.Debug("starting recertification check", zap.String("repo_root", repoRoot), zap.Int("patterns", len(patterns)))
 
+	decorators := make(map[string]*regexp.Regexp)
+	now := c.now()
+
 	for i, file := range files {
 		// Calculate relative path for matching
 		relPath, err := filepath.Rel(repoRoot, file.Path)
@@ -35,7 +74,18 @@ func (c *Checker) Check(files []types.FileInfo, patterns []config.Pattern, repoR
 
 		c.logger.Debug("checking file", zap.Int("index", i+1), zap.String("file", relPath), zap.Time("last_modified", file.LastModified))
 
-		matchedPattern, err := FindMatchingPattern(patterns, relPath)
+		// Multi-file units are matched through their members, since the unit path is a directory
+		matchPath := relPath
+		if len(file.Members) > 0 {
+			memberPath, err := filepath.Rel(repoRoot, file.Members[0])
+			if err != nil {
+				c.logger.Warn("failed to get relative path", zap.String("file", file.Members[0]), zap.Error(err))
+				continue
+			}
+			matchPath = filepath.ToSlash(memberPath)
+		}
+
+		matchedPattern, err := FindMatchingPattern(patterns, matchPath)
 		if err != nil {
 			c.logger.Warn("failed to match pattern", zap.String("file", relPath), zap.Error(err))
 			continue
@@ -48,53 +98,164 @@ func (c *Checker) Check(files []types.FileInfo, patterns []config.Pattern, repoR
 			continue
 		}
 
+		lastCertified := file.LastCertified
+		if matchedPattern.UseDecoratorDate {
+			ts, ok := c.decoratorDate(file, *matchedPattern, decorators)
+			switch {
+			case ok && ts.After(now):
+				// A date in the future would postpone the recertification at will
+				c.logger.Warn("ignoring decorator date in the future", zap.String("file", relPath), zap.String("resource", file.Resource), zap.Time("decorator_date", ts))
+			case ok && ts.After(lastCertified):
+				lastCertified = ts
+			}
+		}
+
 		// Compute recertification status
-		daysSince := int(time.Since(file.LastModified).Hours() / 24)
+		reference := dueReference(matchedPattern.DueBasis, file.LastModified, lastCertified)
 		threshold := matchedPattern.RecertificationDays
+		historyUnknown := file.LastModified.IsZero()
+		if reference.IsZero() {
+			// Without any date the file is treated as due today rather than ~2000 years overdue.
+			// The engine decides what to do with it based on history.unknown_policy.
+			c.logger.Warn("file has no resolvable history", zap.String("file", relPath), zap.String("resource", file.Resource))
+			reference = now.AddDate(0, 0, -threshold)
+		}
+		daysSince := int(now.Sub(reference).Hours() / 24)
 		needsRecert := daysSince >= threshold
 
-		priority := "Low"
-		if needsRecert {
-			ratio := float64(daysSince) / float64(threshold)
-			if ratio > 1.5 {
-				priority = "Critical"
-			} else if ratio >= 1.0 {
-				priority = "High"
-			} else if ratio >= 0.8 {
-				priority = "Medium"
-			}
-		} else {
-			// Check if approaching threshold
-			ratio := float64(daysSince) / float64(threshold)
-			if ratio >= 0.8 {
-				priority = "Medium"
-			}
+		result := types.RecertCheckResult{
+			File:           file,
+			PatternName:    matchedPattern.Name,
+			LastCertified:  lastCertified,
+			DaysSince:      daysSince,
+			Threshold:      threshold,
+			NeedsRecert:    needsRecert,
+			NextDueDate:    reference.AddDate(0, 0, threshold),
+			HistoryUnknown: historyUnknown,
+			RiskScore:      float64(daysSince) / float64(threshold),
+		}
+		if c.scorer != nil {
+			result.RiskScore, result.RiskBreakdown = c.scorer.Score(result, *matchedPattern, relPath)
 		}
 
-		nextDueDate := file.LastModified.AddDate(0, 0, threshold)
+		buckets := c.priorities
+		if len(matchedPattern.Priorities) > 0 {
+			buckets = sortBuckets(matchedPattern.Priorities)
+		}
+		bucket := priorityBucket(buckets, result.RiskScore)
+		result.Priority = bucket.Name
+		result.PriorityLabel = bucket.Label
+		if bucket.SLADays > 0 {
+			result.SLADeadline = result.NextDueDate.AddDate(0, 0, bucket.SLADays)
+		}
 
-		result := types.RecertCheckResult{
-			File:        file,
-			PatternName: matchedPattern.Name,
-			DaysSince:   daysSince,
-			Threshold:   threshold,
-			Priority:    priority,
-			NeedsRecert: needsRecert,
-			NextDueDate: nextDueDate,
+		if exemption := matchExemption(c.exemptions, relPath, matchPath); exemption != nil {
+			result.Exemption = exemption
+			if exemption.Expired(now) {
+				c.logger.Warn("exemption has expired",
+					zap.String("file", relPath),
+					zap.String("reason", exemption.Reason),
+					zap.String("approved_by", exemption.ApprovedBy),
+					zap.Time("expires_at", exemption.ExpiresAt),
+					zap.String("source", exemption.Source))
+			} else {
+				result.Exempted = true
+				result.NeedsRecert = false
+			}
 		}
 
 		results = append(results, result)
 
 		c.logger.Debug("file check result",
 			zap.String("file", relPath),
+			zap.String("resource", file.Resource),
 			zap.String("pattern", matchedPattern.Name),
+			zap.Time("last_certified", lastCertified),
 			zap.Int("days_since", daysSince),
 			zap.Int("threshold", threshold),
-			zap.String("priority", priority),
-			zap.Bool("needs_recert", needsRecert),
-			zap.Time("next_due", nextDueDate))
+			zap.String("priority", result.Priority),
+			zap.Float64("risk_score", result.RiskScore),
+			zap.Bool("needs_recert", result.NeedsRecert),
+			zap.Bool("exempted", result.Exempted),
+			zap.Bool("history_unknown", historyUnknown),
+			zap.Time("next_due", result.NextDueDate))
 	}
 
 	c.logger.Info("recertification check completed", zap.Int("results", len(results)))
 	return results, nil
 }
+
+// decoratorDate parses the certification timestamp from the decorator of the file's pattern.
+// Compiled decorator expressions are cached per pattern.
+func (c *Checker) decoratorDate(file types.FileInfo, pattern config.Pattern, cache map[string]*regexp.Regexp) (time.Time, bool) {
+	re, ok := cache[pattern.Name]
+	if !ok {
+		var err error
+		re, err = DecoratorRegexp(pattern.Decorator)
+		if err != nil {
+			c.logger.Warn("cannot parse decorator dates for pattern", zap.String("pattern", pattern.Name), zap.Error(err))
+		}
+		cache[pattern.Name] = re
+	}
+	if re == nil {
+		return time.Time{}, false
+	}
+
+	paths := []string{file.Path}
+	if len(file.Members) > 0 {
+		paths = file.Members
+	}
+	ts, found, err := readDecoratorDate(re, paths, file.Resource)
+	if err != nil {
+		c.logger.Warn("failed to read decorator", zap.String("file", file.Path), zap.Error(err))
+		return time.Time{}, false
+	}
+	return ts, found
+}
+
+// dueReference returns the date the recertification clock runs from:
+//   - latest (default): whichever of modification and certification is later
+//   - modification: the last modification only
+//   - certification: the last certification, or the modification if never certified
+//   - earliest: whichever is earlier, so the file is due when either clock expires
+func dueReference(basis string, modified, certified time.Time) time.Time {
+	switch basis {
+	case "modification":
+		return modified
+	case "certification":
+		if certified.IsZero() {
+			return modified
+		}
+		return certified
+	case "earliest":
+		if certified.IsZero() || modified.Before(certified) {
+			return modified
+		}
+		return certified
+	default:
+		if certified.After(modified) {
+			return certified
+		}
+		return modified
+	}
+}
+
+// sortBuckets returns a copy of buckets ordered by descending MinRatio.
+func sortBuckets(buckets []config.PriorityBucket) []config.PriorityBucket {
+	sorted := append([]config.PriorityBucket(nil), buckets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MinRatio > sorted[j].MinRatio
+	})
+	return sorted
+}
+
+// priorityBucket returns the first bucket (in descending MinRatio order) whose MinRatio the
+// ratio reaches. A ratio below every bucket gets no priority.
+func priorityBucket(buckets []config.PriorityBucket, ratio float64) config.PriorityBucket {
+	for _, b := range buckets {
+		if ratio >= b.MinRatio {
+			return b
+		}
+	}
+	return config.PriorityBucket{}
+}
@@ -2,6 +2,7 @@ package scan
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -15,7 +16,7 @@ import (
 
 func TestChecker_Check(t *testing.T) {
 	logger := zap.NewNop()
-	checker := NewChecker(logger)
+	checker := NewChecker(nil, nil, logger)
 
 	now := time.Now()
 	repoRoot, _ := filepath.Abs(".")
@@ -156,6 +157,90 @@ func TestChecker_Check(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "terraform module matched through members",
+			files: []types.FileInfo{
+				{
+					Path:         filepath.Join(repoRoot, "modules", "vpc"),
+					LastModified: now.AddDate(0, 0, -70),
+					Members: []string{
+						filepath.Join(repoRoot, "modules", "vpc", "main.tf"),
+						filepath.Join(repoRoot, "modules", "vpc", "variables.tf"),
+					},
+				},
+			},
+			patterns: []config.Pattern{
+				{
+					Name:                "terraform",
+					Enabled:             true,
+					Paths:               []string{"modules/**/*.tf"},
+					RecertificationDays: 60,
+					Mode:                "terraform_module",
+				},
+			},
+			want: []types.RecertCheckResult{
+				{
+					PatternName: "terraform",
+					DaysSince:   70,
+					Threshold:   60,
+					Priority:    "High",
+					NeedsRecert: true,
+				},
+			},
+		},
+		{
+			name: "recent certification resets the clock",
+			files: []types.FileInfo{
+				{
+					Path:          filepath.Join(repoRoot, "main.tf"),
+					LastModified:  now.AddDate(0, 0, -100),
+					LastCertified: now.AddDate(0, 0, -10),
+				},
+			},
+			patterns: []config.Pattern{
+				{
+					Name:                "terraform",
+					Enabled:             true,
+					Paths:               []string{"*.tf"},
+					RecertificationDays: 60,
+				},
+			},
+			want: []types.RecertCheckResult{
+				{
+					PatternName: "terraform",
+					DaysSince:   10,
+					Threshold:   60,
+					Priority:    "Low",
+					NeedsRecert: false,
+				},
+			},
+		},
+		{
+			name: "unknown history is due today",
+			files: []types.FileInfo{
+				{
+					Path: filepath.Join(repoRoot, "main.tf"),
+				},
+			},
+			patterns: []config.Pattern{
+				{
+					Name:                "terraform",
+					Enabled:             true,
+					Paths:               []string{"*.tf"},
+					RecertificationDays: 60,
+				},
+			},
+			want: []types.RecertCheckResult{
+				{
+					PatternName:    "terraform",
+					DaysSince:      60,
+					Threshold:      60,
+					Priority:       "High",
+					NeedsRecert:    true,
+					HistoryUnknown: true,
+				},
+			},
+		},
 		{
 			name: "no match",
 			files: []types.FileInfo{
@@ -194,7 +279,278 @@ func TestChecker_Check(t *testing.T) {
 				assert.Equal(t, want.Threshold, got[i].Threshold)
 				assert.Equal(t, want.Priority, got[i].Priority)
 				assert.Equal(t, want.NeedsRecert, got[i].NeedsRecert)
+				assert.Equal(t, want.HistoryUnknown, got[i].HistoryUnknown)
 			}
 		})
 	}
 }
+
+func TestChecker_Check_DecoratorDate(t *testing.T) {
+	checker := NewChecker(nil, nil, zap.NewNop())
+	repoRoot := t.TempDir()
+
+	certified := time.Now().AddDate(0, 0, -5).UTC().Truncate(time.Second)
+	path := filepath.Join(repoRoot, "main.tf")
+	content := "# Last Recertification: " + certified.Format(time.RFC3339) + "\nresource \"x\" \"y\" {}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	files := []types.FileInfo{{Path: path, LastModified: time.Now().AddDate(0, 0, -100)}}
+	pattern := config.Pattern{
+		Name:                "terraform",
+		Enabled:             true,
+		Paths:               []string{"*.tf"},
+		RecertificationDays: 60,
+		Decorator:           "# Last Recertification: {timestamp}\n",
+		UseDecoratorDate:    true,
+	}
+
+	got, err := checker.Check(files, []config.Pattern{pattern}, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, certified.Equal(got[0].LastCertified))
+	assert.Equal(t, 5, got[0].DaysSince)
+	assert.False(t, got[0].NeedsRecert)
+
+	pattern.DueBasis = "modification"
+	got, err = checker.Check(files, []config.Pattern{pattern}, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 100, got[0].DaysSince)
+	assert.True(t, got[0].NeedsRecert)
+
+	// A decorator date in the future is ignored rather than postponing the recertification
+	pattern.DueBasis = ""
+	future := time.Now().AddDate(1, 0, 0).UTC().Truncate(time.Second)
+	content = "# Last Recertification: " + future.Format(time.RFC3339) + "\nresource \"x\" \"y\" {}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	got, err = checker.Check(files, []config.Pattern{pattern}, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].LastCertified.IsZero())
+	assert.Equal(t, 100, got[0].DaysSince)
+	assert.True(t, got[0].NeedsRecert)
+
+	// Relative to the date of a forecast, the same date is in the past
+	checker.SetClock(func() time.Time { return future.AddDate(0, 0, 10) })
+	got, err = checker.Check(files, []config.Pattern{pattern}, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, future.Equal(got[0].LastCertified))
+	assert.False(t, got[0].NeedsRecert)
+}
+
+func TestChecker_Check_DecoratorDateKubernetesResource(t *testing.T) {
+	checker := NewChecker(nil, nil, zap.NewNop())
+	repoRoot := t.TempDir()
+
+	certified := time.Now().AddDate(0, 0, -5).UTC().Truncate(time.Second)
+	path := filepath.Join(repoRoot, "app.yaml")
+	content := "kind: Service\nmetadata:\n  name: web\n---\n# Last Recertification: " + certified.Format(time.RFC3339) + "\nkind: ConfigMap\nmetadata:\n  name: settings\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	modified := time.Now().AddDate(0, 0, -100)
+	files := []types.FileInfo{
+		{Path: path, Resource: "Service/web", StartLine: 1, EndLine: 3, LastModified: modified},
+		{Path: path, Resource: "ConfigMap/settings", StartLine: 5, EndLine: 8, LastModified: modified},
+	}
+	pattern := config.Pattern{
+		Name:                "k8s",
+		Enabled:             true,
+		Paths:               []string{"*.yaml"},
+		RecertificationDays: 60,
+		Mode:                "kubernetes_resource",
+		Decorator:           "# Last Recertification: {timestamp}\n",
+		UseDecoratorDate:    true,
+	}
+
+	// Only the resource whose document carries the decorator is certified by it
+	got, err := checker.Check(files, []config.Pattern{pattern}, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.True(t, got[0].NeedsRecert)
+	assert.False(t, got[1].NeedsRecert)
+}
+
+func TestChecker_Check_PriorityBuckets(t *testing.T) {
+	repoRoot, _ := filepath.Abs(".")
+	now := time.Now()
+	files := []types.FileInfo{
+		{Path: filepath.Join(repoRoot, "prod/iam.tf"), LastModified: now.AddDate(0, 0, -60)},
+		{Path: filepath.Join(repoRoot, "dev/main.tf"), LastModified: now.AddDate(0, 0, -60)},
+		{Path: filepath.Join(repoRoot, "dev/vpc.tf"), LastModified: now.AddDate(0, 0, -20)},
+	}
+	patterns := []config.Pattern{
+		{
+			Name:                "prod-iam",
+			Enabled:             true,
+			Paths:               []string{"prod/*.tf"},
+			RecertificationDays: 60,
+			Priorities: []config.PriorityBucket{
+				{Name: "Low", MinRatio: 0},
+				{Name: "Critical", MinRatio: 1.0, SLADays: 7, Label: "priority/critical"},
+			},
+		},
+		{
+			Name:                "dev",
+			Enabled:             true,
+			Paths:               []string{"dev/*.tf"},
+			RecertificationDays: 60,
+		},
+	}
+	global := []config.PriorityBucket{
+		{Name: "Urgent", MinRatio: 1.0, SLADays: 30},
+		{Name: "Routine", MinRatio: 0},
+	}
+
+	checker := NewChecker(global, nil, zap.NewNop())
+	got, err := checker.Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "Critical", got[0].Priority)
+	assert.Equal(t, "priority/critical", got[0].PriorityLabel)
+	assert.Equal(t, got[0].NextDueDate.AddDate(0, 0, 7), got[0].SLADeadline)
+
+	assert.Equal(t, "Urgent", got[1].Priority)
+	assert.Empty(t, got[1].PriorityLabel)
+	assert.Equal(t, got[1].NextDueDate.AddDate(0, 0, 30), got[1].SLADeadline)
+
+	assert.Equal(t, "Routine", got[2].Priority)
+	assert.True(t, got[2].SLADeadline.IsZero())
+}
+
+// fixedScorer doubles the overdue ratio of every result.
+type fixedScorer struct{}
+
+func (fixedScorer) Score(result types.RecertCheckResult, pattern config.Pattern, relPath string) (float64, []types.RiskFactor) {
+	ratio := float64(result.DaysSince) / float64(result.Threshold)
+	return ratio * 2, []types.RiskFactor{{Name: "overdue", Factor: ratio}, {Name: "pattern", Detail: pattern.Name, Factor: 2}}
+}
+
+func TestChecker_Check_RiskScore(t *testing.T) {
+	repoRoot, _ := filepath.Abs(".")
+	files := []types.FileInfo{
+		{Path: filepath.Join(repoRoot, "main.tf"), LastModified: time.Now().AddDate(0, 0, -48)},
+	}
+	patterns := []config.Pattern{
+		{Name: "terraform", Enabled: true, Paths: []string{"*.tf"}, RecertificationDays: 60},
+	}
+
+	got, err := NewChecker(nil, nil, zap.NewNop()).Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.InDelta(t, 0.8, got[0].RiskScore, 1e-9)
+	assert.Equal(t, "Medium", got[0].Priority)
+	assert.Empty(t, got[0].RiskBreakdown)
+
+	// The priority follows the score rather than the ratio
+	got, err = NewChecker(nil, fixedScorer{}, zap.NewNop()).Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.6, got[0].RiskScore, 1e-9)
+	assert.Equal(t, "Critical", got[0].Priority)
+	assert.False(t, got[0].NeedsRecert)
+	assert.Len(t, got[0].RiskBreakdown, 2)
+}
+
+func TestChecker_Check_Exemptions(t *testing.T) {
+	repoRoot, _ := filepath.Abs(".")
+	now := time.Now()
+	files := []types.FileInfo{
+		{Path: filepath.Join(repoRoot, "legacy/main.tf"), LastModified: now.AddDate(0, 0, -100)},
+		{Path: filepath.Join(repoRoot, "frozen/main.tf"), LastModified: now.AddDate(0, 0, -100)},
+		{Path: filepath.Join(repoRoot, "prod/main.tf"), LastModified: now.AddDate(0, 0, -100)},
+	}
+	patterns := []config.Pattern{
+		{Name: "terraform", Enabled: true, Paths: []string{"**/*.tf"}, RecertificationDays: 60},
+	}
+
+	checker := NewChecker(nil, nil, zap.NewNop())
+	checker.SetExemptions([]types.Exemption{
+		{Paths: []string{"legacy/**"}, Reason: "decommission", ApprovedBy: "jane", ExpiresAt: now.AddDate(0, 1, 0)},
+		{Paths: []string{"frozen/**"}, Reason: "migration", ApprovedBy: "joe", ExpiresAt: now.AddDate(0, 0, -1)},
+	})
+	got, err := checker.Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	assert.True(t, got[0].Exempted)
+	assert.False(t, got[0].NeedsRecert)
+	require.NotNil(t, got[0].Exemption)
+	assert.Equal(t, "decommission", got[0].Exemption.Reason)
+
+	// Expired exemptions are kept for reporting but no longer apply
+	assert.False(t, got[1].Exempted)
+	assert.True(t, got[1].NeedsRecert)
+	require.NotNil(t, got[1].Exemption)
+	assert.Equal(t, "migration", got[1].Exemption.Reason)
+
+	assert.False(t, got[2].Exempted)
+	assert.Nil(t, got[2].Exemption)
+	assert.True(t, got[2].NeedsRecert)
+
+	t.Run("renewal overrides expired exemption", func(t *testing.T) {
+		checker := NewChecker(nil, nil, zap.NewNop())
+		checker.SetExemptions([]types.Exemption{
+			{Paths: []string{"frozen/**"}, Reason: "migration", ApprovedBy: "joe", ExpiresAt: now.AddDate(0, 0, -1), Source: "/etc/ice/exemptions.yaml"},
+			{Paths: []string{"frozen/main.tf"}, Reason: "migration extended", ApprovedBy: "joe", ExpiresAt: now.AddDate(0, 2, 0), Source: ".ice-exemptions.yaml"},
+		})
+		got, err := checker.Check(files[1:2], patterns, repoRoot)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.True(t, got[0].Exempted)
+		assert.False(t, got[0].NeedsRecert)
+		assert.Equal(t, "migration extended", got[0].Exemption.Reason)
+	})
+}
+
+func TestChecker_Check_Clock(t *testing.T) {
+	repoRoot, _ := filepath.Abs(".")
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := []types.FileInfo{{Path: filepath.Join(repoRoot, "main.tf"), LastModified: modified}}
+	patterns := []config.Pattern{
+		{Name: "terraform", Enabled: true, Paths: []string{"*.tf"}, RecertificationDays: 90},
+	}
+
+	checker := NewChecker(nil, nil, zap.NewNop())
+	checker.SetClock(func() time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) })
+	got, err := checker.Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 59, got[0].DaysSince)
+	assert.False(t, got[0].NeedsRecert)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), got[0].NextDueDate)
+
+	checker.SetClock(func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) })
+	got, err = checker.Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	assert.Equal(t, 151, got[0].DaysSince)
+	assert.True(t, got[0].NeedsRecert)
+}
+
+func TestChecker_Check_BelowEveryPriorityBucket(t *testing.T) {
+	repoRoot, _ := filepath.Abs(".")
+	now := time.Now()
+	files := []types.FileInfo{
+		{Path: filepath.Join(repoRoot, "prod/iam.tf"), LastModified: now.AddDate(0, 0, -20)},
+	}
+	patterns := []config.Pattern{
+		{
+			Name:                "prod-iam",
+			Enabled:             true,
+			Paths:               []string{"prod/*.tf"},
+			RecertificationDays: 60,
+			Priorities: []config.PriorityBucket{
+				{Name: "Critical", MinRatio: 1.0, SLADays: 7, Label: "priority/critical"},
+			},
+		},
+	}
+
+	checker := NewChecker(nil, nil, zap.NewNop())
+	got, err := checker.Check(files, patterns, repoRoot)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	assert.Empty(t, got[0].Priority)
+	assert.Empty(t, got[0].PriorityLabel)
+	assert.True(t, got[0].SLADeadline.IsZero())
+}
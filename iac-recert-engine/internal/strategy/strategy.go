@@ -1,18 +1,67 @@
 package strategy
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/plugin"
 	"github.com/baldator/iac-recert-engine/internal/types"
 	"go.uber.org/zap"
 )
 
 type Strategy interface {
-	Group(results []types.RecertCheckResult) ([]types.FileGroup, error)
+	Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error)
 }
 
-func NewStrategy(cfg config.PRStrategyConfig, logger *zap.Logger) (Strategy, error) {
+// OwnerResolver resolves the owners of a group of files, as assign.Resolver does.
+type OwnerResolver interface {
+	Resolve(ctx context.Context, group types.FileGroup) (types.AssignmentResult, error)
+}
+
+// NewStrategy creates the configured strategy, splitting its groups by priority when
+// split_by_priority or min_priority is set, merging groups smaller than min_files_per_pr and
+// splitting groups larger than max_files_per_pr. Priorities
+// are the global buckets from the most to the least urgent; the owner resolver is only used by
+// the per_owner strategy.
+func NewStrategy(cfg config.PRStrategyConfig, priorities []config.PriorityBucket, pm *plugin.Manager, owners OwnerResolver, logger *zap.Logger) (Strategy, error) {
+	strategy, err := newBaseStrategy(cfg, pm, owners, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SplitByPriority || cfg.MinPriority != "" {
+		ranks := make(map[string]int, len(priorities))
+		for i, bucket := range priorities {
+			ranks[bucket.Name] = i
+		}
+		if _, ok := ranks[cfg.MinPriority]; cfg.MinPriority != "" && !ok {
+			return nil, fmt.Errorf("unknown min_priority %q", cfg.MinPriority)
+		}
+		strategy = &PriorityStrategy{base: strategy, ranks: ranks, floor: cfg.MinPriority, split: cfg.SplitByPriority, logger: logger}
+	}
+	if cfg.MinFilesPerPR > 1 {
+		strategy = &MergeStrategy{base: strategy, minFiles: cfg.MinFilesPerPR, byPattern: cfg.MergeSmallGroups == "pattern", byPriority: cfg.SplitByPriority, logger: logger}
+	}
+	if cfg.MaxFilesPerPR > 0 {
+		strategy = &SplitStrategy{base: strategy, maxFiles: cfg.MaxFilesPerPR, logger: logger}
+	}
+	return strategy, nil
+}
+
+func newBaseStrategy(cfg config.PRStrategyConfig, pm *plugin.Manager, owners OwnerResolver, logger *zap.Logger) (Strategy, error) {
+	if len(cfg.GroupBy) > 0 {
+		if cfg.Type != "" {
+			return nil, fmt.Errorf("pr_strategy type and group_by are mutually exclusive")
+		}
+		return newGroupByStrategy(cfg, owners, logger)
+	}
+
 	switch cfg.Type {
 	case "per_file":
 		return &PerFileStrategy{logger: logger}, nil
@@ -20,10 +69,18 @@ func NewStrategy(cfg config.PRStrategyConfig, logger *zap.Logger) (Strategy, err
 		return &PerPatternStrategy{logger: logger}, nil
 	case "per_committer":
 		return &PerCommitterStrategy{logger: logger}, nil
+	case "per_directory":
+		return newPerDirectoryStrategy(cfg, cfg.DirectoryDepth, logger)
+	case "per_owner":
+		return &PerOwnerStrategy{resolver: owners, logger: logger}, nil
 	case "single_pr":
 		return &SinglePRStrategy{logger: logger}, nil
 	case "plugin":
-		return nil, fmt.Errorf("plugin strategy not implemented yet")
+		gp, err := pm.GetGroupingPlugin(cfg.PluginName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get grouping plugin: %w", err)
+		}
+		return &PluginStrategy{name: cfg.PluginName, plugin: gp, logger: logger}, nil
 	default:
 		return nil, fmt.Errorf("unknown strategy type: %s", cfg.Type)
 	}
@@ -33,33 +90,47 @@ type PerFileStrategy struct {
 	logger *zap.Logger
 }
 
-func (s *PerFileStrategy) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+func (s *PerFileStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
 	s.logger.Debug("grouping files individually", zap.Int("total_results", len(results)))
 
+	// Kubernetes resources of one manifest are recertified in the same PR
 	var groups []types.FileGroup
+	index := make(map[string]int)
 	for _, res := range results {
 		if !res.NeedsRecert {
 			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
 			continue
 		}
-		group := types.FileGroup{
-			ID:       fmt.Sprintf("file-%s", res.File.Path),
+		id := fmt.Sprintf("file-%s", fileKey(res.File))
+		if i, ok := index[id]; ok {
+			groups[i].Files = append(groups[i].Files, res)
+			continue
+		}
+		index[id] = len(groups)
+		groups = append(groups, types.FileGroup{
+			ID:       id,
 			Strategy: "per_file",
 			Files:    []types.RecertCheckResult{res},
-		}
-		groups = append(groups, group)
-		s.logger.Debug("created file group", zap.String("file", res.File.Path), zap.String("group_id", group.ID))
+		})
+		s.logger.Debug("created file group", zap.String("file", res.File.Path), zap.String("group_id", id))
 	}
 
 	s.logger.Debug("per-file grouping completed", zap.Int("groups_created", len(groups)))
 	return groups, nil
 }
 
+// fileKey identifies a file by its repo-relative path so the key doesn't depend on the location
+// of the clone. Kubernetes resources share the key of their manifest, whose decorators are
+// written in a single commit.
+func fileKey(file types.FileInfo) string {
+	return file.RepoPath()
+}
+
 type PerPatternStrategy struct {
 	logger *zap.Logger
 }
 
-func (s *PerPatternStrategy) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+func (s *PerPatternStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
 	s.logger.Debug("grouping files by pattern", zap.Int("total_results", len(results)))
 
 	groups := make(map[string][]types.RecertCheckResult)
@@ -90,7 +161,7 @@ type PerCommitterStrategy struct {
 	logger *zap.Logger
 }
 
-func (s *PerCommitterStrategy) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+func (s *PerCommitterStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
 	s.logger.Debug("grouping files by committer", zap.Int("total_results", len(results)))
 
 	groups := make(map[string][]types.RecertCheckResult)
@@ -99,10 +170,7 @@ func (s *PerCommitterStrategy) Group(results []types.RecertCheckResult) ([]types
 			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
 			continue
 		}
-		author := res.File.CommitAuthor
-		if author == "" {
-			author = "unknown"
-		}
+		author := committerKey(res.File)
 		groups[author] = append(groups[author], res)
 	}
 
@@ -121,11 +189,316 @@ func (s *PerCommitterStrategy) Group(results []types.RecertCheckResult) ([]types
 	return fileGroups, nil
 }
 
+// committerKey returns the last committer of a file, or "unknown".
+func committerKey(file types.FileInfo) string {
+	if file.CommitAuthor == "" {
+		return "unknown"
+	}
+	return file.CommitAuthor
+}
+
+// PerDirectoryStrategy groups files by directory: either the first depth directories of their
+// parent directory, or the captures of pattern matched against the repo-relative path, so that
+// terraform/(?P<env>[^/]+)/(?P<app>[^/]+)/ gives one group per app and environment. Files the
+// pattern doesn't match are grouped by their parent directory.
+type PerDirectoryStrategy struct {
+	depth   int
+	pattern *regexp.Regexp
+	logger  *zap.Logger
+}
+
+func newPerDirectoryStrategy(cfg config.PRStrategyConfig, depth int, logger *zap.Logger) (*PerDirectoryStrategy, error) {
+	s := &PerDirectoryStrategy{depth: depth, logger: logger}
+	if cfg.DirectoryPattern != "" {
+		re, err := regexp.Compile(cfg.DirectoryPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directory_pattern: %w", err)
+		}
+		s.pattern = re
+	}
+	return s, nil
+}
+
+func (s *PerDirectoryStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	s.logger.Debug("grouping files by directory", zap.Int("total_results", len(results)), zap.Int("depth", s.depth))
+
+	groups := make(map[string][]types.RecertCheckResult)
+	for _, res := range results {
+		if !res.NeedsRecert {
+			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
+			continue
+		}
+		key := s.directoryKey(res.File)
+		groups[key] = append(groups[key], res)
+	}
+
+	var fileGroups []types.FileGroup
+	for key, files := range groups {
+		group := types.FileGroup{
+			ID:       fmt.Sprintf("dir-%s", key),
+			Strategy: "per_directory",
+			Files:    files,
+		}
+		fileGroups = append(fileGroups, group)
+		s.logger.Debug("created directory group", zap.String("directory", key), zap.Int("files", len(files)), zap.String("group_id", group.ID))
+	}
+
+	s.logger.Debug("directory grouping completed", zap.Int("groups_created", len(fileGroups)))
+	return fileGroups, nil
+}
+
+// directoryKey returns the group key of a file: the captures joined by slashes, named ones as
+// name=value and empty ones kept so that each capture keeps its position, or its directory
+// truncated to depth elements. Files in the repository root share the key ".", which no
+// directory can be named.
+func (s *PerDirectoryStrategy) directoryKey(file types.FileInfo) string {
+	relPath := file.RepoPath()
+
+	if s.pattern != nil {
+		if m := s.pattern.FindStringSubmatch(relPath); m != nil {
+			if len(m) == 1 {
+				return strings.Trim(m[0], "/")
+			}
+			names := s.pattern.SubexpNames()
+			values := make([]string, 0, len(m)-1)
+			for i, v := range m[1:] {
+				if name := names[i+1]; name != "" {
+					v = name + "=" + v
+				}
+				values = append(values, v)
+			}
+			return strings.Join(values, "/")
+		}
+		s.logger.Debug("directory pattern did not match, grouping by parent directory", zap.String("file", relPath))
+	}
+
+	// A Terraform module is a directory already
+	dir := relPath
+	if len(file.Members) == 0 {
+		dir = path.Dir(relPath)
+	}
+	if dir == "." || dir == "/" {
+		return "."
+	}
+	if parts := strings.Split(dir, "/"); s.depth > 0 && len(parts) > s.depth {
+		dir = strings.Join(parts[:s.depth], "/")
+	}
+	return dir
+}
+
+// PerOwnerStrategy resolves the owners of each file through the assignment config and groups
+// files by their set of assignees, so each owner gets one PR with everything they own. The
+// resolved assignees and reviewers are kept on the group rather than resolved again per PR.
+type PerOwnerStrategy struct {
+	resolver OwnerResolver
+	logger   *zap.Logger
+}
+
+func (s *PerOwnerStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	s.logger.Debug("grouping files by owner", zap.Int("total_results", len(results)))
+
+	groups := make(map[string]*types.FileGroup)
+	var keys []string
+	for _, res := range results {
+		if !res.NeedsRecert {
+			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
+			continue
+		}
+
+		assignment, err := resolveOwners(ctx, s.resolver, res)
+		if err != nil {
+			return nil, err
+		}
+		key := ownerKey(assignment)
+		group, ok := groups[key]
+		if !ok {
+			group = &types.FileGroup{
+				ID:            fmt.Sprintf("owner-%s", key),
+				Strategy:      "per_owner",
+				Assignees:     assignment.Assignees,
+				TeamReviewers: assignment.TeamReviewers,
+			}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Files = append(group.Files, res)
+		addAssignment(group, assignment)
+	}
+
+	var fileGroups []types.FileGroup
+	for _, key := range keys {
+		fileGroups = append(fileGroups, *groups[key])
+		s.logger.Debug("created owner group", zap.String("owners", key), zap.Int("files", len(groups[key].Files)), zap.String("group_id", groups[key].ID))
+	}
+
+	s.logger.Debug("owner grouping completed", zap.Int("groups_created", len(fileGroups)))
+	return fileGroups, nil
+}
+
+// resolveOwners resolves the assignment of a single file, with its assignees and teams sorted.
+func resolveOwners(ctx context.Context, resolver OwnerResolver, res types.RecertCheckResult) (types.AssignmentResult, error) {
+	if resolver == nil {
+		return types.AssignmentResult{}, fmt.Errorf("no owner resolver configured")
+	}
+	fileGroup := types.FileGroup{ID: fmt.Sprintf("file-%s", fileKey(res.File)), Files: []types.RecertCheckResult{res}}
+	assignment, err := resolver.Resolve(ctx, fileGroup)
+	if err != nil {
+		return types.AssignmentResult{}, fmt.Errorf("failed to resolve owners of %s: %w", res.File.Path, err)
+	}
+	assignment.Assignees = uniqueSorted(assignment.Assignees)
+	assignment.TeamReviewers = uniqueSorted(assignment.TeamReviewers)
+	return assignment, nil
+}
+
+// addAssignment merges the reviewers and assignment rules of a file into its group.
+func addAssignment(group *types.FileGroup, assignment types.AssignmentResult) {
+	if len(assignment.Reviewers) > 0 {
+		group.Reviewers = uniqueSorted(append(group.Reviewers, assignment.Reviewers...))
+	}
+	if len(assignment.Rules) > 0 {
+		group.AssignmentRules = uniqueSorted(append(group.AssignmentRules, assignment.Rules...))
+	}
+}
+
+// ownerKey joins the sorted assignees and teams of an assignment with "+", or returns
+// "unassigned".
+func ownerKey(assignment types.AssignmentResult) string {
+	owners := uniqueSorted(append(append([]string(nil), assignment.Assignees...), assignment.TeamReviewers...))
+	if len(owners) == 0 {
+		return "unassigned"
+	}
+	return strings.Join(owners, "+")
+}
+
+// GroupByStrategy groups files by a combination of keys, e.g. [pattern, directory:2, committer]
+// gives one group per pattern, top two directories and last committer. The group ID joins one
+// segment per key in order, using the ID of the matching preset strategy as segment, such as
+// pattern-terraform-dir-envs/prod-author-alice. With the owner key the resolved assignees and
+// reviewers are kept on the group, as with per_owner.
+type GroupByStrategy struct {
+	keys      []string
+	directory map[string]*PerDirectoryStrategy // By directory key, for its depth
+	resolver  OwnerResolver
+	logger    *zap.Logger
+}
+
+func newGroupByStrategy(cfg config.PRStrategyConfig, owners OwnerResolver, logger *zap.Logger) (*GroupByStrategy, error) {
+	s := &GroupByStrategy{directory: make(map[string]*PerDirectoryStrategy), resolver: owners, logger: logger}
+	seen := make(map[string]bool)
+	for _, key := range cfg.GroupBy {
+		name, arg, hasArg := strings.Cut(key, ":")
+		switch {
+		case name == "directory":
+			depth := cfg.DirectoryDepth
+			if hasArg {
+				d, err := strconv.Atoi(arg)
+				if err != nil || d < 1 {
+					return nil, fmt.Errorf("invalid group_by key %q: depth must be a positive integer", key)
+				}
+				depth = d
+			}
+			dir, err := newPerDirectoryStrategy(cfg, depth, logger)
+			if err != nil {
+				return nil, err
+			}
+			s.directory[key] = dir
+		case hasArg:
+			return nil, fmt.Errorf("invalid group_by key %q: only directory takes an argument", key)
+		case name == "owner" && owners == nil:
+			return nil, fmt.Errorf("group_by key owner requires an owner resolver")
+		case name != "file" && name != "pattern" && name != "committer" && name != "owner":
+			return nil, fmt.Errorf("unknown group_by key %q", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate group_by key %q", key)
+		}
+		seen[key] = true
+		s.keys = append(s.keys, key)
+	}
+	return s, nil
+}
+
+func (s *GroupByStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	s.logger.Debug("grouping files by keys", zap.Int("total_results", len(results)), zap.Strings("keys", s.keys))
+
+	groups := make(map[string]*types.FileGroup)
+	var ids []string
+	for _, res := range results {
+		if !res.NeedsRecert {
+			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
+			continue
+		}
+
+		var segments []string
+		var assignment *types.AssignmentResult
+		for _, key := range s.keys {
+			switch key {
+			case "file":
+				segments = append(segments, "file-"+fileKey(res.File))
+			case "pattern":
+				segments = append(segments, "pattern-"+res.PatternName)
+			case "committer":
+				segments = append(segments, "author-"+committerKey(res.File))
+			case "owner":
+				resolved, err := resolveOwners(ctx, s.resolver, res)
+				if err != nil {
+					return nil, err
+				}
+				assignment = &resolved
+				segments = append(segments, "owner-"+ownerKey(resolved))
+			default:
+				segments = append(segments, "dir-"+s.directory[key].directoryKey(res.File))
+			}
+		}
+
+		id := strings.Join(segments, "-")
+		group, ok := groups[id]
+		if !ok {
+			group = &types.FileGroup{ID: id, Strategy: "group_by"}
+			if assignment != nil {
+				group.Assignees = assignment.Assignees
+				group.TeamReviewers = assignment.TeamReviewers
+			}
+			groups[id] = group
+			ids = append(ids, id)
+		}
+		group.Files = append(group.Files, res)
+		if assignment != nil {
+			addAssignment(group, *assignment)
+		}
+	}
+
+	// Sort for a deterministic order independent of the order of the results
+	sort.Strings(ids)
+	var fileGroups []types.FileGroup
+	for _, id := range ids {
+		fileGroups = append(fileGroups, *groups[id])
+		s.logger.Debug("created group", zap.String("group_id", id), zap.Int("files", len(groups[id].Files)))
+	}
+
+	s.logger.Debug("key grouping completed", zap.Int("groups_created", len(fileGroups)))
+	return fileGroups, nil
+}
+
+// uniqueSorted returns the distinct values in sorted order.
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
 type SinglePRStrategy struct {
 	logger *zap.Logger
 }
 
-func (s *SinglePRStrategy) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+func (s *SinglePRStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
 	s.logger.Debug("grouping all files into single PR", zap.Int("total_results", len(results)))
 
 	var files []types.RecertCheckResult
@@ -151,3 +524,302 @@ func (s *SinglePRStrategy) Group(results []types.RecertCheckResult) ([]types.Fil
 	s.logger.Debug("created single PR group", zap.Int("files", len(files)), zap.String("group_id", group.ID))
 	return []types.FileGroup{group}, nil
 }
+
+// PluginStrategy delegates grouping to a grouping plugin, e.g. to group files by CMDB
+// application or cost center.
+type PluginStrategy struct {
+	name   string
+	plugin plugin.GroupingPlugin
+	logger *zap.Logger
+}
+
+func (s *PluginStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	s.logger.Debug("grouping files with plugin", zap.String("plugin", s.name), zap.Int("total_results", len(results)))
+
+	var due []types.RecertCheckResult
+	for _, res := range results {
+		if res.NeedsRecert {
+			due = append(due, res)
+		} else {
+			s.logger.Debug("skipping file that doesn't need recertification", zap.String("file", res.File.Path))
+		}
+	}
+	if len(due) == 0 {
+		s.logger.Debug("no files need recertification, no groups created")
+		return nil, nil
+	}
+
+	pluginGroups, err := s.plugin.Group(due)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed to group files: %w", s.name, err)
+	}
+
+	grouped := make(map[string]bool)
+	var fileGroups []types.FileGroup
+	index := make(map[string]int) // Plugin group name to position in fileGroups
+	for _, pg := range pluginGroups {
+		if err := validatePluginGroupName(pg.ID); err != nil {
+			return nil, fmt.Errorf("plugin %s returned an invalid group: %w", s.name, err)
+		}
+		var files []types.RecertCheckResult
+		for _, res := range pg.Files {
+			key := res.File.Path + "#" + res.File.Resource
+			if grouped[key] {
+				s.logger.Warn("plugin placed file in several groups, keeping the first", zap.String("file", res.File.Path), zap.String("group", pg.ID))
+				continue
+			}
+			grouped[key] = true
+			files = append(files, res)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		// Groups sharing a name share a group ID and so a branch, so they are merged
+		if i, ok := index[pg.ID]; ok {
+			fileGroups[i].Files = append(fileGroups[i].Files, files...)
+			s.logger.Debug("merged plugin group with an earlier group of the same name", zap.String("group", pg.ID), zap.Int("files", len(files)))
+			continue
+		}
+		index[pg.ID] = len(fileGroups)
+		group := types.FileGroup{
+			ID:       fmt.Sprintf("group-%s", pg.ID),
+			Strategy: "plugin",
+			Files:    files,
+		}
+		fileGroups = append(fileGroups, group)
+		s.logger.Debug("created plugin group", zap.String("group", pg.ID), zap.Int("files", len(files)), zap.String("group_id", group.ID))
+	}
+
+	// Files the plugin left out still need recertification, so they share a PR
+	var ungrouped []types.RecertCheckResult
+	for _, res := range due {
+		if !grouped[res.File.Path+"#"+res.File.Resource] {
+			ungrouped = append(ungrouped, res)
+		}
+	}
+	if len(ungrouped) > 0 {
+		s.logger.Warn("plugin left files ungrouped", zap.String("plugin", s.name), zap.Int("files", len(ungrouped)))
+		fileGroups = append(fileGroups, types.FileGroup{
+			ID:       "group-ungrouped",
+			Strategy: "plugin",
+			Files:    ungrouped,
+		})
+	}
+
+	s.logger.Debug("plugin grouping completed", zap.Int("groups_created", len(fileGroups)))
+	return fileGroups, nil
+}
+
+// validatePluginGroupName rejects group names that are empty, clash with the group of the files a
+// plugin leaves out, or would nest branches or escape a ref component. Other characters git
+// rejects, e.g. spaces in CMDB application names, are replaced when the branch is sanitized.
+func validatePluginGroupName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("group name is empty")
+	case name == "ungrouped":
+		return fmt.Errorf("group name %q is reserved for the files left out of every group", name)
+	case strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") || strings.ContainsFunc(name, unicode.IsControl):
+		return fmt.Errorf("group name %q must not contain slashes, \"..\" or control characters", name)
+	}
+	return nil
+}
+
+// PriorityStrategy leaves results below the floor priority out of the base strategy's groups
+// and, when split is set, splits each group by priority, appending the priority to the group ID.
+// Priorities unknown to ranks, such as those of pattern-specific buckets, sort after the known
+// ones; the engine rejects them when a floor is set. Results without a priority are below every
+// floor.
+type PriorityStrategy struct {
+	base   Strategy
+	ranks  map[string]int // Priority name to position, 0 for the most urgent
+	floor  string
+	split  bool
+	logger *zap.Logger
+}
+
+func (s *PriorityStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	if s.floor != "" {
+		var kept []types.RecertCheckResult
+		for _, res := range results {
+			if rank, ok := s.ranks[res.Priority]; res.Priority == "" || ok && rank > s.ranks[s.floor] {
+				s.logger.Debug("skipping file below the priority floor", zap.String("file", res.File.Path), zap.String("priority", res.Priority), zap.String("min_priority", s.floor))
+				continue
+			}
+			kept = append(kept, res)
+		}
+		results = kept
+	}
+
+	groups, err := s.base.Group(ctx, results)
+	if err != nil || !s.split {
+		return groups, err
+	}
+
+	var split []types.FileGroup
+	for _, g := range groups {
+		byPriority := make(map[string][]types.RecertCheckResult)
+		var priorities []string
+		for _, f := range g.Files {
+			if _, ok := byPriority[f.Priority]; !ok {
+				priorities = append(priorities, f.Priority)
+			}
+			byPriority[f.Priority] = append(byPriority[f.Priority], f)
+		}
+		sort.SliceStable(priorities, func(i, j int) bool {
+			return s.rank(priorities[i]) < s.rank(priorities[j])
+		})
+
+		for _, priority := range priorities {
+			group := g
+			group.ID = fmt.Sprintf("%s-%s", g.ID, prioritySlug(priority))
+			group.Files = byPriority[priority]
+			split = append(split, group)
+		}
+		s.logger.Debug("split group by priority", zap.String("group_id", g.ID), zap.Strings("priorities", priorities))
+	}
+	return split, nil
+}
+
+func (s *PriorityStrategy) rank(priority string) int {
+	if rank, ok := s.ranks[priority]; ok {
+		return rank
+	}
+	return len(s.ranks)
+}
+
+// prioritySlug turns a priority name into a lowercase group ID and branch name component.
+func prioritySlug(priority string) string {
+	if priority == "" {
+		return "unprioritized"
+	}
+	return strings.Join(strings.FieldsFunc(strings.ToLower(priority), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// MergeStrategy folds the groups of a base strategy with fewer than minFiles files into a
+// catch-all small-groups group or, with byPattern, into one group per pattern. With byPriority
+// the merged groups keep the priority split. Merged groups are resolved again for assignment,
+// since they can span owners.
+type MergeStrategy struct {
+	base       Strategy
+	minFiles   int
+	byPattern  bool
+	byPriority bool
+	logger     *zap.Logger
+}
+
+func (s *MergeStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	groups, err := s.base.Group(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []types.FileGroup
+	merged := make(map[string]*types.FileGroup)
+	var mergedIDs []string
+	for _, g := range groups {
+		if len(g.Files) >= s.minFiles {
+			kept = append(kept, g)
+			continue
+		}
+		s.logger.Debug("merging small group", zap.String("group_id", g.ID), zap.Int("files", len(g.Files)), zap.Int("min_files", s.minFiles))
+		for _, f := range g.Files {
+			id := "small-groups"
+			if s.byPattern {
+				id = fmt.Sprintf("pattern-%s", f.PatternName)
+			}
+			if s.byPriority {
+				id = fmt.Sprintf("%s-%s", id, prioritySlug(f.Priority))
+			}
+			group, ok := merged[id]
+			if !ok {
+				group = &types.FileGroup{ID: id, Strategy: g.Strategy}
+				merged[id] = group
+				mergedIDs = append(mergedIDs, id)
+			}
+			group.Files = append(group.Files, f)
+		}
+	}
+
+	for _, id := range mergedIDs {
+		kept = append(kept, *merged[id])
+		s.logger.Debug("created merged group", zap.String("group_id", id), zap.Int("files", len(merged[id].Files)))
+	}
+	return kept, nil
+}
+
+// SplitStrategy splits the groups of a base strategy with more than maxFiles files into
+// evenly sized chunks. Files are chunked in path order, so the same files always produce the
+// same chunks, and chunk IDs such as pattern-x-part-1 are stable between runs. The number of
+// chunks is left out of the ID, so a group growing by a chunk keeps the branches of the others.
+type SplitStrategy struct {
+	base     Strategy
+	maxFiles int
+	logger   *zap.Logger
+}
+
+func (s *SplitStrategy) Group(ctx context.Context, results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	groups, err := s.base.Group(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+
+	var split []types.FileGroup
+	for _, g := range groups {
+		if len(g.Files) <= s.maxFiles {
+			split = append(split, g)
+			continue
+		}
+
+		files := append([]types.RecertCheckResult(nil), g.Files...)
+		sort.SliceStable(files, func(i, j int) bool {
+			if files[i].File.Path != files[j].File.Path {
+				return files[i].File.Path < files[j].File.Path
+			}
+			return files[i].File.Resource < files[j].File.Resource
+		})
+
+		// Fill the chunks one after another, so a file added or removed only shifts the chunks
+		// after it and the earlier ones keep their files
+		parts := (len(files) + s.maxFiles - 1) / s.maxFiles
+		for part := 1; part <= parts; part++ {
+			start := (part - 1) * s.maxFiles
+			end := min(start+s.maxFiles, len(files))
+			chunk := g
+			chunk.ID = fmt.Sprintf("%s-part-%d", g.ID, part)
+			chunk.Files = files[start:end]
+			chunk.Part = part
+			chunk.Parts = parts
+			split = append(split, chunk)
+		}
+		s.logger.Debug("split large group", zap.String("group_id", g.ID), zap.Int("files", len(files)), zap.Int("parts", parts))
+	}
+	return split, nil
+}
+
+// OrderByRisk sorts the files of each group and the groups themselves by descending risk score.
+// A group ranks by its riskiest file; ties keep a deterministic order by group ID.
+func OrderByRisk(groups []types.FileGroup) {
+	for _, g := range groups {
+		sort.SliceStable(g.Files, func(i, j int) bool {
+			return g.Files[i].RiskScore > g.Files[j].RiskScore
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		si, sj := maxRiskScore(groups[i]), maxRiskScore(groups[j])
+		if si != sj {
+			return si > sj
+		}
+		return groups[i].ID < groups[j].ID
+	})
+}
+
+func maxRiskScore(group types.FileGroup) float64 {
+	score := 0.0
+	for _, f := range group.Files {
+		score = max(score, f.RiskScore)
+	}
+	return score
+}
@@ -1,8 +1,15 @@
 package strategy
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/baldator/iac-recert-engine/internal/config"
+	"github.com/baldator/iac-recert-engine/internal/plugin"
 	"github.com/baldator/iac-recert-engine/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,7 +35,7 @@ func TestPerFileStrategy_Group(t *testing.T) {
 		},
 	}
 
-	groups, err := s.Group(results)
+	groups, err := s.Group(context.Background(), results)
 	require.NoError(t, err)
 	require.Len(t, groups, 2)
 
@@ -41,6 +48,23 @@ func TestPerFileStrategy_Group(t *testing.T) {
 	assert.Len(t, groups[1].Files, 1)
 }
 
+func TestPerFileStrategy_GroupKubernetesResources(t *testing.T) {
+	s := &PerFileStrategy{logger: zap.NewNop()}
+
+	groups, err := s.Group(context.Background(), []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/tmp/scan/k8s/app.yaml", RelPath: "k8s/app.yaml", Resource: "Service/web"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/tmp/scan/k8s/db.yaml", RelPath: "k8s/db.yaml", Resource: "Service/db"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/tmp/scan/k8s/app.yaml", RelPath: "k8s/app.yaml", Resource: "ConfigMap/settings"}, NeedsRecert: true},
+	})
+	require.NoError(t, err)
+
+	// Resources of one manifest share a PR, whose commit decorates each of them
+	require.Len(t, groups, 2)
+	assert.Equal(t, "file-k8s/app.yaml", groups[0].ID)
+	assert.Len(t, groups[0].Files, 2)
+	assert.Equal(t, "file-k8s/db.yaml", groups[1].ID)
+}
+
 func TestPerPatternStrategy_Group(t *testing.T) {
 	logger := zap.NewNop()
 	s := &PerPatternStrategy{logger: logger}
@@ -68,7 +92,7 @@ func TestPerPatternStrategy_Group(t *testing.T) {
 		},
 	}
 
-	groups, err := s.Group(results)
+	groups, err := s.Group(context.Background(), results)
 	require.NoError(t, err)
 	require.Len(t, groups, 2)
 
@@ -114,7 +138,7 @@ func TestPerCommitterStrategy_Group(t *testing.T) {
 		},
 	}
 
-	groups, err := s.Group(results)
+	groups, err := s.Group(context.Background(), results)
 	require.NoError(t, err)
 	require.Len(t, groups, 2)
 
@@ -136,6 +160,219 @@ func TestPerCommitterStrategy_Group(t *testing.T) {
 	assert.Len(t, bobGroup.Files, 1)
 }
 
+func TestPerDirectoryStrategy_Group(t *testing.T) {
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/scan/terraform/prod/payments/main.tf", RelPath: "terraform/prod/payments/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/terraform/prod/payments/iam/roles.tf", RelPath: "terraform/prod/payments/iam/roles.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/terraform/dev/payments/main.tf", RelPath: "terraform/dev/payments/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/terraform/prod/billing", RelPath: "terraform/prod/billing", Members: []string{"/scan/terraform/prod/billing/main.tf"}}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/k8s/app.yaml", RelPath: "k8s/app.yaml"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/main.tf", RelPath: "main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/terraform/dev/billing/main.tf", RelPath: "terraform/dev/billing/main.tf"}, NeedsRecert: false},
+	}
+
+	t.Run("parent directory", func(t *testing.T) {
+		s := &PerDirectoryStrategy{logger: zap.NewNop()}
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_directory", groups)
+		assert.Equal(t, map[string]int{
+			"dir-terraform/prod/payments":     1,
+			"dir-terraform/prod/payments/iam": 1,
+			"dir-terraform/dev/payments":      1,
+			"dir-terraform/prod/billing":      1,
+			"dir-k8s":                         1,
+			"dir-.":                           1,
+		}, sizes)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		s := &PerDirectoryStrategy{depth: 2, logger: zap.NewNop()}
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_directory", groups)
+		assert.Equal(t, map[string]int{
+			"dir-terraform/prod": 3,
+			"dir-terraform/dev":  1,
+			"dir-k8s":            1,
+			"dir-.":              1,
+		}, sizes)
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		cfg := config.PRStrategyConfig{Type: "per_directory", DirectoryPattern: `^terraform/(?P<env>[^/]+)/(?P<app>[^/]+)`}
+		s, err := NewStrategy(cfg, nil, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_directory", groups)
+		assert.Equal(t, map[string]int{
+			"dir-env=prod/app=payments": 2,
+			"dir-env=dev/app=payments":  1,
+			"dir-env=prod/app=billing":  1,
+			"dir-k8s":                   1,
+			"dir-.":                     1,
+		}, sizes)
+	})
+
+	t.Run("pattern with empty and unnamed captures", func(t *testing.T) {
+		// Without its position an empty app would give terraform/prod/billing the key of the
+		// directory terraform/prod
+		cfg := config.PRStrategyConfig{Type: "per_directory", DirectoryPattern: `^terraform/([^/]+)/(?:(?P<app>payments)/)?`}
+		s, err := NewStrategy(cfg, nil, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_directory", groups)
+		assert.Equal(t, map[string]int{
+			"dir-prod/app=payments": 2,
+			"dir-dev/app=payments":  1,
+			"dir-prod/app=":         1,
+			"dir-k8s":               1,
+			"dir-.":                 1,
+		}, sizes)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := NewStrategy(config.PRStrategyConfig{Type: "per_directory", DirectoryPattern: "(unclosed"}, nil, nil, nil, zap.NewNop())
+		assert.ErrorContains(t, err, "invalid directory_pattern")
+	})
+}
+
+// ownerResolver assigns files by the first directory of their path.
+type ownerResolver struct {
+	owners map[string][]string
+}
+
+func (r ownerResolver) Resolve(_ context.Context, group types.FileGroup) (types.AssignmentResult, error) {
+	dir := strings.SplitN(group.Files[0].File.RelPath, "/", 2)[0]
+	if dir == "broken" {
+		return types.AssignmentResult{}, errors.New("lookup failed")
+	}
+	return types.AssignmentResult{Assignees: r.owners[dir], Reviewers: []string{dir + "-reviewer"}}, nil
+}
+
+func TestPerOwnerStrategy_Group(t *testing.T) {
+	resolver := ownerResolver{owners: map[string][]string{
+		"network":  {"team-net"},
+		"payments": {"team-pay", "alice"},
+		"billing":  {"alice", "team-pay"},
+	}}
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/scan/network/vpc.tf", RelPath: "network/vpc.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/payments/main.tf", RelPath: "payments/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/billing/main.tf", RelPath: "billing/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/misc/main.tf", RelPath: "misc/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/network/old.tf", RelPath: "network/old.tf"}, NeedsRecert: false},
+	}
+
+	s, err := NewStrategy(config.PRStrategyConfig{Type: "per_owner"}, nil, nil, resolver, zap.NewNop())
+	require.NoError(t, err)
+	groups, err := s.Group(context.Background(), results)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "owner-team-net", groups[0].ID)
+	assert.Equal(t, "per_owner", groups[0].Strategy)
+	assert.Equal(t, []string{"team-net"}, groups[0].Assignees)
+	assert.Len(t, groups[0].Files, 1)
+
+	assert.Equal(t, "owner-alice+team-pay", groups[1].ID)
+	assert.Equal(t, []string{"alice", "team-pay"}, groups[1].Assignees)
+	assert.Equal(t, []string{"billing-reviewer", "payments-reviewer"}, groups[1].Reviewers)
+	assert.Len(t, groups[1].Files, 2)
+
+	assert.Equal(t, "owner-unassigned", groups[2].ID)
+	assert.Empty(t, groups[2].Assignees)
+
+	_, err = s.Group(context.Background(), []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/scan/broken/main.tf", RelPath: "broken/main.tf"}, NeedsRecert: true},
+	})
+	assert.ErrorContains(t, err, "failed to resolve owners of /scan/broken/main.tf")
+}
+
+func TestGroupByStrategy_Group(t *testing.T) {
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "/scan/envs/prod/app/main.tf", RelPath: "envs/prod/app/main.tf", CommitAuthor: "alice"}, PatternName: "terraform", NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/envs/prod/db/main.tf", RelPath: "envs/prod/db/main.tf", CommitAuthor: "alice"}, PatternName: "terraform", NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/envs/prod/db/vars.tf", RelPath: "envs/prod/db/vars.tf", CommitAuthor: "bob"}, PatternName: "terraform", NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/envs/dev/app/main.tf", RelPath: "envs/dev/app/main.tf"}, PatternName: "terraform", NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/envs/prod/app.yaml", RelPath: "envs/prod/app.yaml", CommitAuthor: "alice"}, PatternName: "k8s", NeedsRecert: true},
+		{File: types.FileInfo{Path: "/scan/envs/prod/old.tf", RelPath: "envs/prod/old.tf", CommitAuthor: "alice"}, PatternName: "terraform", NeedsRecert: false},
+	}
+
+	t.Run("combined keys", func(t *testing.T) {
+		cfg := config.PRStrategyConfig{GroupBy: []string{"pattern", "directory:2", "committer"}}
+		s, err := NewStrategy(cfg, nil, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, sizes := groupSizes(t, "group_by", groups)
+		assert.Equal(t, []string{
+			"pattern-k8s-dir-envs/prod-author-alice",
+			"pattern-terraform-dir-envs/dev-author-unknown",
+			"pattern-terraform-dir-envs/prod-author-alice",
+			"pattern-terraform-dir-envs/prod-author-bob",
+		}, ids)
+		assert.Equal(t, map[string]int{
+			"pattern-k8s-dir-envs/prod-author-alice":        1,
+			"pattern-terraform-dir-envs/dev-author-unknown": 1,
+			"pattern-terraform-dir-envs/prod-author-alice":  2,
+			"pattern-terraform-dir-envs/prod-author-bob":    1,
+		}, sizes)
+	})
+
+	t.Run("single key matches its preset", func(t *testing.T) {
+		s, err := NewStrategy(config.PRStrategyConfig{GroupBy: []string{"directory"}, DirectoryDepth: 3}, nil, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		preset, err := NewStrategy(config.PRStrategyConfig{Type: "per_directory", DirectoryDepth: 3}, nil, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		presetGroups, err := preset.Group(context.Background(), results)
+		require.NoError(t, err)
+
+		ids, _ := groupSizes(t, "group_by", groups)
+		var presetIDs []string
+		for _, g := range presetGroups {
+			presetIDs = append(presetIDs, g.ID)
+		}
+		assert.ElementsMatch(t, presetIDs, ids)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		resolver := ownerResolver{owners: map[string][]string{"envs": {"team-infra"}}}
+		s, err := NewStrategy(config.PRStrategyConfig{GroupBy: []string{"owner", "pattern"}}, nil, nil, resolver, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, sizes := groupSizes(t, "group_by", groups)
+		assert.Equal(t, []string{"owner-team-infra-pattern-k8s", "owner-team-infra-pattern-terraform"}, ids)
+		assert.Equal(t, map[string]int{
+			"owner-team-infra-pattern-k8s":       1,
+			"owner-team-infra-pattern-terraform": 4,
+		}, sizes)
+		assert.Equal(t, []string{"team-infra"}, groups[1].Assignees)
+		assert.Equal(t, []string{"envs-reviewer"}, groups[1].Reviewers)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for key, want := range map[string]string{
+			"directory:0": `invalid group_by key "directory:0"`,
+			"pattern:2":   `invalid group_by key "pattern:2"`,
+			"team":        `unknown group_by key "team"`,
+			"owner":       "group_by key owner requires an owner resolver",
+		} {
+			_, err := NewStrategy(config.PRStrategyConfig{GroupBy: []string{key}}, nil, nil, nil, zap.NewNop())
+			assert.ErrorContains(t, err, want, key)
+		}
+		_, err := NewStrategy(config.PRStrategyConfig{GroupBy: []string{"pattern", "pattern"}}, nil, nil, nil, zap.NewNop())
+		assert.ErrorContains(t, err, `duplicate group_by key "pattern"`)
+		_, err = NewStrategy(config.PRStrategyConfig{Type: "per_file", GroupBy: []string{"pattern"}}, nil, nil, nil, zap.NewNop())
+		assert.ErrorContains(t, err, "mutually exclusive")
+	})
+}
+
 func TestSinglePRStrategy_Group(t *testing.T) {
 	logger := zap.NewNop()
 	s := &SinglePRStrategy{logger: logger}
@@ -155,7 +392,7 @@ func TestSinglePRStrategy_Group(t *testing.T) {
 		},
 	}
 
-	groups, err := s.Group(results)
+	groups, err := s.Group(context.Background(), results)
 	require.NoError(t, err)
 	require.Len(t, groups, 1)
 
@@ -163,3 +400,337 @@ func TestSinglePRStrategy_Group(t *testing.T) {
 	assert.Equal(t, "single_pr", groups[0].Strategy)
 	assert.Len(t, groups[0].Files, 2)
 }
+
+// dirPlugin groups files by their first path element and leaves out files in the root.
+type dirPlugin struct{}
+
+func (dirPlugin) Init(map[string]string) error { return nil }
+
+func (dirPlugin) Group(results []types.RecertCheckResult) ([]types.FileGroup, error) {
+	var groups []types.FileGroup
+	index := make(map[string]int)
+	for _, res := range results {
+		dir, _, ok := strings.Cut(res.File.Path, "/")
+		if !ok {
+			continue
+		}
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			groups = append(groups, types.FileGroup{ID: dir})
+		}
+		groups[i].Files = append(groups[i].Files, res)
+	}
+	// A file placed twice is only kept in its first group
+	if len(groups) > 1 {
+		groups[1].Files = append(groups[1].Files, groups[0].Files[0])
+	}
+	return groups, nil
+}
+
+func TestPluginStrategy_Group(t *testing.T) {
+	s := &PluginStrategy{name: "dirs", plugin: dirPlugin{}, logger: zap.NewNop()}
+
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "payments/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "billing/main.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "payments/vpc.tf"}, NeedsRecert: true},
+		{File: types.FileInfo{Path: "payments/iam.tf"}, NeedsRecert: false},
+		{File: types.FileInfo{Path: "main.tf"}, NeedsRecert: true},
+	}
+
+	groups, err := s.Group(context.Background(), results)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "group-payments", groups[0].ID)
+	assert.Equal(t, "plugin", groups[0].Strategy)
+	assert.Len(t, groups[0].Files, 2)
+
+	assert.Equal(t, "group-billing", groups[1].ID)
+	require.Len(t, groups[1].Files, 1)
+	assert.Equal(t, "billing/main.tf", groups[1].Files[0].File.Path)
+
+	assert.Equal(t, "group-ungrouped", groups[2].ID)
+	require.Len(t, groups[2].Files, 1)
+	assert.Equal(t, "main.tf", groups[2].Files[0].File.Path)
+}
+
+// fixedPlugin returns its groups regardless of the results.
+type fixedPlugin []types.FileGroup
+
+func (fixedPlugin) Init(map[string]string) error { return nil }
+
+func (p fixedPlugin) Group([]types.RecertCheckResult) ([]types.FileGroup, error) { return p, nil }
+
+func TestPluginStrategy_GroupNames(t *testing.T) {
+	a := types.RecertCheckResult{File: types.FileInfo{Path: "a.tf"}, NeedsRecert: true}
+	b := types.RecertCheckResult{File: types.FileInfo{Path: "b.tf"}, NeedsRecert: true}
+	c := types.RecertCheckResult{File: types.FileInfo{Path: "c.tf"}, NeedsRecert: true}
+	results := []types.RecertCheckResult{a, b, c}
+
+	t.Run("same name merged", func(t *testing.T) {
+		s := &PluginStrategy{name: "apps", plugin: fixedPlugin{
+			{ID: "payments", Files: []types.RecertCheckResult{a}},
+			{ID: "billing", Files: []types.RecertCheckResult{b}},
+			{ID: "payments", Files: []types.RecertCheckResult{c}},
+		}, logger: zap.NewNop()}
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, sizes := groupSizes(t, "plugin", groups)
+		assert.Equal(t, []string{"group-payments", "group-billing"}, ids)
+		assert.Equal(t, map[string]int{"group-payments": 2, "group-billing": 1}, sizes)
+	})
+
+	t.Run("name sanitized for the branch", func(t *testing.T) {
+		s := &PluginStrategy{name: "apps", plugin: fixedPlugin{
+			{ID: "Payments App", Files: []types.RecertCheckResult{a}},
+		}, logger: zap.NewNop()}
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, _ := groupSizes(t, "plugin", groups)
+		assert.Equal(t, []string{"group-Payments App", "group-ungrouped"}, ids)
+	})
+
+	for _, name := range []string{"", "envs/prod", `envs\prod`, "..", "app\n", "ungrouped"} {
+		t.Run("invalid "+name, func(t *testing.T) {
+			s := &PluginStrategy{name: "apps", plugin: fixedPlugin{
+				{ID: name, Files: []types.RecertCheckResult{a}},
+			}, logger: zap.NewNop()}
+			_, err := s.Group(context.Background(), results)
+			assert.ErrorContains(t, err, "plugin apps returned an invalid group")
+		})
+	}
+}
+
+func TestNewStrategy_Plugin(t *testing.T) {
+	pm, err := plugin.NewManager(nil, zap.NewNop())
+	require.NoError(t, err)
+
+	_, err = NewStrategy(config.PRStrategyConfig{Type: "plugin", PluginName: "missing"}, nil, pm, nil, zap.NewNop())
+	assert.ErrorContains(t, err, "plugin not found: missing")
+}
+
+func TestPriorityStrategy_Group(t *testing.T) {
+	priorities := []config.PriorityBucket{{Name: "Critical"}, {Name: "High"}, {Name: "Medium"}, {Name: "Low"}}
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "a.tf"}, PatternName: "terraform", Priority: "Medium", NeedsRecert: true},
+		{File: types.FileInfo{Path: "b.tf"}, PatternName: "terraform", Priority: "Critical", NeedsRecert: true},
+		{File: types.FileInfo{Path: "c.tf"}, PatternName: "terraform", Priority: "Low", NeedsRecert: true},
+		{File: types.FileInfo{Path: "d.tf"}, PatternName: "terraform", Priority: "Very Urgent", NeedsRecert: true},
+		{File: types.FileInfo{Path: "e.tf"}, PatternName: "terraform", Priority: "Medium", NeedsRecert: true},
+	}
+
+	t.Run("split", func(t *testing.T) {
+		s, err := NewStrategy(config.PRStrategyConfig{Type: "per_pattern", SplitByPriority: true}, priorities, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, sizes := groupSizes(t, "", groups)
+		assert.Equal(t, []string{"pattern-terraform-critical", "pattern-terraform-medium", "pattern-terraform-low", "pattern-terraform-very-urgent"}, ids)
+		assert.Equal(t, map[string]int{
+			"pattern-terraform-critical":    1,
+			"pattern-terraform-medium":      2,
+			"pattern-terraform-low":         1,
+			"pattern-terraform-very-urgent": 1,
+		}, sizes)
+	})
+
+	t.Run("floor", func(t *testing.T) {
+		s, err := NewStrategy(config.PRStrategyConfig{Type: "per_pattern", MinPriority: "Medium"}, priorities, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, sizes := groupSizes(t, "", groups)
+		assert.Equal(t, []string{"pattern-terraform"}, ids)
+		assert.Equal(t, map[string]int{"pattern-terraform": 4}, sizes)
+	})
+
+	t.Run("split with floor and size limit", func(t *testing.T) {
+		cfg := config.PRStrategyConfig{Type: "single_pr", SplitByPriority: true, MinPriority: "High", MaxFilesPerPR: 1}
+		s, err := NewStrategy(cfg, priorities, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), results)
+		require.NoError(t, err)
+		ids, _ := groupSizes(t, "", groups)
+		assert.Equal(t, []string{"all-files-critical", "all-files-very-urgent"}, ids)
+	})
+
+	t.Run("no priority below floor", func(t *testing.T) {
+		s, err := NewStrategy(config.PRStrategyConfig{Type: "per_pattern", MinPriority: "Low"}, priorities, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		groups, err := s.Group(context.Background(), []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "a.tf"}, PatternName: "terraform", Priority: "Low", NeedsRecert: true},
+			{File: types.FileInfo{Path: "b.tf"}, PatternName: "terraform", NeedsRecert: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Len(t, groups[0].Files, 1)
+		assert.Equal(t, "a.tf", groups[0].Files[0].File.Path)
+	})
+
+	t.Run("unknown floor", func(t *testing.T) {
+		_, err := NewStrategy(config.PRStrategyConfig{Type: "per_file", MinPriority: "Urgent"}, priorities, nil, nil, zap.NewNop())
+		assert.ErrorContains(t, err, `unknown min_priority "Urgent"`)
+	})
+}
+
+func TestMergeStrategy_Group(t *testing.T) {
+	results := []types.RecertCheckResult{
+		{File: types.FileInfo{Path: "a.tf", CommitAuthor: "alice"}, PatternName: "terraform", Priority: "High", NeedsRecert: true},
+		{File: types.FileInfo{Path: "b.tf", CommitAuthor: "alice"}, PatternName: "terraform", Priority: "High", NeedsRecert: true},
+		{File: types.FileInfo{Path: "c.tf", CommitAuthor: "alice"}, PatternName: "terraform", Priority: "Low", NeedsRecert: true},
+		{File: types.FileInfo{Path: "d.tf", CommitAuthor: "bob"}, PatternName: "terraform", Priority: "High", NeedsRecert: true},
+		{File: types.FileInfo{Path: "e.yaml", CommitAuthor: "carol"}, PatternName: "k8s", Priority: "Low", NeedsRecert: true},
+		{File: types.FileInfo{Path: "f.yaml", CommitAuthor: "dave"}, PatternName: "k8s", Priority: "High", NeedsRecert: true},
+	}
+	newStrategy := func(cfg config.PRStrategyConfig) Strategy {
+		cfg.Type = "per_committer"
+		s, err := NewStrategy(cfg, []config.PriorityBucket{{Name: "High"}, {Name: "Low"}}, nil, nil, zap.NewNop())
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("catch-all", func(t *testing.T) {
+		groups, err := newStrategy(config.PRStrategyConfig{MinFilesPerPR: 2}).Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_committer", groups)
+		assert.Equal(t, map[string]int{"author-alice": 3, "small-groups": 3}, sizes)
+	})
+
+	t.Run("catch-all above the size limit", func(t *testing.T) {
+		groups, err := newStrategy(config.PRStrategyConfig{MinFilesPerPR: 2, MaxFilesPerPR: 2}).Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_committer", groups)
+		assert.Equal(t, map[string]int{
+			"author-alice-part-1": 2,
+			"author-alice-part-2": 1,
+			"small-groups-part-1": 2,
+			"small-groups-part-2": 1,
+		}, sizes)
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		groups, err := newStrategy(config.PRStrategyConfig{MinFilesPerPR: 2, MergeSmallGroups: "pattern"}).Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_committer", groups)
+		assert.Equal(t, map[string]int{"author-alice": 3, "pattern-terraform": 1, "pattern-k8s": 2}, sizes)
+	})
+
+	t.Run("priority split and size limit", func(t *testing.T) {
+		cfg := config.PRStrategyConfig{MinFilesPerPR: 2, SplitByPriority: true, MaxFilesPerPR: 2}
+		groups, err := newStrategy(cfg).Group(context.Background(), results)
+		require.NoError(t, err)
+		_, sizes := groupSizes(t, "per_committer", groups)
+		assert.Equal(t, map[string]int{
+			"author-alice-high": 2,
+			"small-groups-high": 2,
+			"small-groups-low":  2,
+		}, sizes)
+	})
+}
+
+func TestSplitStrategy_Group(t *testing.T) {
+	s := &SplitStrategy{base: &PerPatternStrategy{logger: zap.NewNop()}, maxFiles: 4, logger: zap.NewNop()}
+
+	var results []types.RecertCheckResult
+	for _, i := range []int{7, 2, 9, 0, 4, 1, 8, 3, 6, 5} {
+		results = append(results, types.RecertCheckResult{
+			File:        types.FileInfo{Path: fmt.Sprintf("file%d.tf", i)},
+			PatternName: "terraform",
+			NeedsRecert: true,
+		})
+	}
+	results = append(results, types.RecertCheckResult{File: types.FileInfo{Path: "README.md"}, PatternName: "docs", NeedsRecert: true})
+
+	groups, err := s.Group(context.Background(), results)
+	require.NoError(t, err)
+	require.Len(t, groups, 4)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+
+	assert.Equal(t, "pattern-docs", groups[0].ID)
+	assert.Zero(t, groups[0].Parts)
+
+	// 10 files in chunks of at most 4 are split 4, 4, 2 in path order
+	paths := func(g types.FileGroup) []string {
+		var p []string
+		for _, f := range g.Files {
+			p = append(p, f.File.Path)
+		}
+		return p
+	}
+	assert.Equal(t, "pattern-terraform-part-1", groups[1].ID)
+	assert.Equal(t, []string{"file0.tf", "file1.tf", "file2.tf", "file3.tf"}, paths(groups[1]))
+	assert.Equal(t, 1, groups[1].Part)
+	assert.Equal(t, 3, groups[1].Parts)
+	assert.Equal(t, "pattern-terraform-part-2", groups[2].ID)
+	assert.Equal(t, []string{"file4.tf", "file5.tf", "file6.tf", "file7.tf"}, paths(groups[2]))
+	assert.Equal(t, "pattern-terraform-part-3", groups[3].ID)
+	assert.Equal(t, []string{"file8.tf", "file9.tf"}, paths(groups[3]))
+	assert.Equal(t, "per_pattern", groups[3].Strategy)
+
+	// A new file only changes the chunk it lands in and those after it
+	results = append(results, types.RecertCheckResult{File: types.FileInfo{Path: "file5a.tf"}, PatternName: "terraform", NeedsRecert: true})
+	groups, err = s.Group(context.Background(), results)
+	require.NoError(t, err)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	assert.Equal(t, []string{"file0.tf", "file1.tf", "file2.tf", "file3.tf"}, paths(groups[1]))
+	assert.Equal(t, []string{"file4.tf", "file5.tf", "file5a.tf", "file6.tf"}, paths(groups[2]))
+	assert.Equal(t, []string{"file7.tf", "file8.tf", "file9.tf"}, paths(groups[3]))
+
+	// A fourth chunk leaves the IDs of the first three unchanged
+	for _, name := range []string{"file9a.tf", "file9b.tf"} {
+		results = append(results, types.RecertCheckResult{File: types.FileInfo{Path: name}, PatternName: "terraform", NeedsRecert: true})
+	}
+	groups, err = s.Group(context.Background(), results)
+	require.NoError(t, err)
+	require.Len(t, groups, 5)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	for i, id := range []string{"pattern-terraform-part-1", "pattern-terraform-part-2", "pattern-terraform-part-3", "pattern-terraform-part-4"} {
+		assert.Equal(t, id, groups[i+1].ID)
+		assert.Equal(t, i+1, groups[i+1].Part)
+		assert.Equal(t, 4, groups[i+1].Parts)
+	}
+	assert.Equal(t, []string{"file0.tf", "file1.tf", "file2.tf", "file3.tf"}, paths(groups[1]))
+	assert.Equal(t, []string{"file9b.tf"}, paths(groups[4]))
+}
+
+func TestOrderByRisk(t *testing.T) {
+	groups := []types.FileGroup{
+		{ID: "pattern-dev", Files: []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "dev/a.tf"}, RiskScore: 1.1},
+		}},
+		{ID: "pattern-prod", Files: []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "prod/a.tf"}, RiskScore: 1.2},
+			{File: types.FileInfo{Path: "prod/b.tf"}, RiskScore: 3.0},
+		}},
+		{ID: "pattern-app", Files: []types.RecertCheckResult{
+			{File: types.FileInfo{Path: "app/a.tf"}, RiskScore: 1.1},
+		}},
+	}
+
+	OrderByRisk(groups)
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "pattern-prod", groups[0].ID)
+	assert.Equal(t, "prod/b.tf", groups[0].Files[0].File.Path)
+	assert.Equal(t, "pattern-app", groups[1].ID)
+	assert.Equal(t, "pattern-dev", groups[2].ID)
+}
+
+// groupSizes returns the IDs of groups in order and the number of files of each, checking each
+// group was created by strategy unless it is empty.
+func groupSizes(t *testing.T, strategy string, groups []types.FileGroup) ([]string, map[string]int) {
+	t.Helper()
+	var ids []string
+	sizes := make(map[string]int)
+	for _, g := range groups {
+		if strategy != "" {
+			assert.Equal(t, strategy, g.Strategy)
+		}
+		ids = append(ids, g.ID)
+		sizes[g.ID] = len(g.Files)
+	}
+	return ids, sizes
+}
@@ -5,31 +5,87 @@ import (
 )
 
 type FileInfo struct {
-	Path         string
-	Size         int64
-	LastModified time.Time
-	CommitHash   string
-	CommitAuthor string
-	CommitEmail  string
-	CommitMsg    string
+	Path          string
+	RelPath       string // Path relative to the repository root, with forward slashes
+	Size          int64
+	LastModified  time.Time
+	LastCertified time.Time // Most recent recertification commit, not counted as a modification
+	CommitHash    string
+	CommitAuthor  string
+	CommitEmail   string
+	CommitMsg     string
+	Members       []string // Files making up a multi-file unit such as a Terraform module
+	Resource      string   // kind/namespace/name for a single Kubernetes object within Path
+	StartLine     int      // First line of Resource within Path (1-based)
+	EndLine       int      // Last line of Resource within Path (1-based)
+	RecentCommits int      // Commits counting as modifications in the last 90 days
+	RecentAuthors int      // Distinct authors of such commits in the last year
+}
+
+// RepoPath returns the repo-relative path of the file, or Path when it is unknown, e.g. for
+// files not found by the scanner.
+func (f FileInfo) RepoPath() string {
+	if f.RelPath == "" {
+		return f.Path
+	}
+	return f.RelPath
 }
 
 type RecertCheckResult struct {
-	File        FileInfo
-	PatternName string
-	DaysSince   int
-	Threshold   int
-	Priority    string // Critical, High, Medium, Low
-	NeedsRecert bool
-	NextDueDate time.Time
+	File          FileInfo
+	PatternName   string
+	LastCertified time.Time // Most recent certification, from recertification commits or the decorator
+	DaysSince     int
+	Threshold     int
+	Priority      string // Name of the priority bucket, by default Critical, High, Medium or Low
+	PriorityLabel string // PR label of the priority bucket, if any
+	NeedsRecert   bool
+	NextDueDate   time.Time
+	SLADeadline   time.Time // NextDueDate plus the bucket's SLA, zero when the bucket has none
+	RiskScore     float64   // Overdue ratio weighted by the risk factors; equals the ratio without risk scoring
+	RiskBreakdown []RiskFactor
+	// HistoryUnknown is set when no modification date could be resolved for the file. Such
+	// files are treated as due today; history.unknown_policy decides whether they get PRs.
+	HistoryUnknown bool
+	// Exemption is the matching exemption that expires last. While it is active the result is
+	// Exempted and not due; an expired exemption is kept so it can be reported.
+	Exemption *Exemption
+	Exempted  bool
+}
+
+// Exemption excludes files matching Paths from recertification until ExpiresAt.
+type Exemption struct {
+	Paths      []string // Glob patterns relative to the repository root
+	Reason     string
+	ApprovedBy string
+	ExpiresAt  time.Time // Last moment the exemption applies
+	Source     string    // File the exemption was loaded from
+}
+
+// Expired reports whether the exemption no longer applies at now.
+func (e Exemption) Expired(now time.Time) bool {
+	return now.After(e.ExpiresAt)
+}
+
+// RiskFactor is one multiplier of a risk score.
+type RiskFactor struct {
+	Name   string  // overdue, pattern, environment, size, churn, authors
+	Detail string  // Human-readable input, e.g. "prod" or "4 commits"
+	Factor float64 // Multiplier applied to the score
 }
 
 type FileGroup struct {
-	ID        string
-	Strategy  string
-	Files     []RecertCheckResult
-	Assignees []string
-	Reviewers []string
+	ID            string
+	Strategy      string
+	Files         []RecertCheckResult
+	Assignees     []string
+	Reviewers     []string
+	TeamReviewers []string
+	// AssignmentRules are the composite assignment rules that matched when the strategy
+	// resolved Assignees while grouping, e.g. per_owner
+	AssignmentRules []string
+	Part            int // 1-based position among the chunks of a group split by max_files_per_pr
+	Parts           int // Number of chunks the group was split into, 0 when not split
 }
 
 type ExecutionResult struct {
@@ -46,6 +102,21 @@ type AssignmentResult struct {
 	Reviewers []string
 	Team      string
 	Priority  string
+	Rules     []string // Patterns of the composite assignment rules that matched files
+	// TeamReviewers are teams (org/team) whose review is requested, e.g. from CODEOWNERS
+	TeamReviewers []string
+	// RoundRobin holds the decisions of the round_robin strategy, one per composite rule using it
+	RoundRobin []RoundRobinDecision
+}
+
+// RoundRobinDecision records which member the round_robin strategy picked for a group and why
+// the others were skipped.
+type RoundRobinDecision struct {
+	GroupID string
+	Branch  string // Branch of the group's PR, recorded for the member once the PR is created
+	Member  string
+	Reason  string            // rotation, pr_open or no_member_available
+	Skipped map[string]string // Member -> unavailable or open_pr_cap
 }
 
 type PRConfig struct {
@@ -56,7 +127,9 @@ type PRConfig struct {
 	Files       []string // List of files included
 	Assignees   []string
 	Reviewers   []string
-	Labels      []string
+	// TeamReviewers are requested as team reviews where the provider supports them
+	TeamReviewers []string
+	Labels        []string
 }
 
 type PullRequest struct {
@@ -68,11 +141,14 @@ type PullRequest struct {
 }
 
 type Commit struct {
-	Hash      string
-	Author    string
-	Email     string
-	Message   string
-	Timestamp time.Time
+	Hash         string
+	Author       string
+	Email        string
+	Message      string
+	Timestamp    time.Time
+	Path         string // Path of the file in this commit, when listed while following renames
+	PreviousPath string // Path of the file before this commit, set when the commit renamed it
+	PureRename   bool   // The commit renamed the file without changing its content
 }
 
 type Change struct {
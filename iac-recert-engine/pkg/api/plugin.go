@@ -11,15 +11,63 @@ type AssignmentPlugin interface {
 	Resolve(files []FileInfo) (AssignmentResult, error)
 }
 
+// GroupingPlugin represents a plugin that groups the files due for recertification into PRs
+type GroupingPlugin interface {
+	Plugin
+	Group(results []RecertCheckResult) ([]Group, error)
+}
+
+// RiskPlugin represents a plugin that scores the risk of files due for recertification. The
+// score replaces the overdue ratio when picking the priority bucket, so a file of average risk
+// should score its overdue ratio (DaysSince / Threshold).
+type RiskPlugin interface {
+	Plugin
+	Score(result RecertCheckResult) (RiskScore, error)
+}
+
 // FileInfo contains information about a file
 type FileInfo struct {
 	Path         string
+	RelPath      string // Path relative to the repository root
 	Size         int64
 	LastModified string // ISO 8601 format
 	CommitHash   string
 	CommitAuthor string
 	CommitEmail  string
 	CommitMsg    string
+	Resource     string // kind/namespace/name when the file is a single Kubernetes object of Path
+}
+
+// RecertCheckResult contains the recertification status of a file
+type RecertCheckResult struct {
+	File        FileInfo
+	PatternName string
+	DaysSince   int
+	Threshold   int
+	Priority    string
+	NextDueDate string // ISO 8601 format
+	RiskScore   float64
+}
+
+// Group is a named set of files to recertify in a single PR. Files are those of the results
+// passed to GroupingPlugin.Group; files left out of every group are grouped together by ICE.
+type Group struct {
+	Name  string
+	Files []FileInfo
+}
+
+// RiskScore is the score of a file with the factors it is the product of, shown in the PR body
+// and run report.
+type RiskScore struct {
+	Score   float64
+	Factors []RiskFactor
+}
+
+// RiskFactor is one factor of a risk score, e.g. {"environment", "prod", 1.5}
+type RiskFactor struct {
+	Name   string
+	Detail string
+	Factor float64
 }
 
 // AssignmentResult contains the assignment information
@@ -31,6 +31,24 @@ func NewCSVLookupPlugin(logger *zap.Logger) api.AssignmentPlugin {
 	}
 }
 
+// NewCSVLookupGroupingPlugin creates a CSV lookup plugin that groups files by the looked-up
+// value, such as the CMDB application or cost center of the key found in each file
+func NewCSVLookupGroupingPlugin(logger *zap.Logger) api.GroupingPlugin {
+	return &CSVLookupPlugin{
+		logger:  logger,
+		csvData: make(map[string]string),
+	}
+}
+
+// NewCSVLookupRiskPlugin creates a CSV lookup plugin that weights the overdue ratio of each file
+// by the looked-up value, such as the criticality of the file's CMDB application
+func NewCSVLookupRiskPlugin(logger *zap.Logger) api.RiskPlugin {
+	return &CSVLookupPlugin{
+		logger:  logger,
+		csvData: make(map[string]string),
+	}
+}
+
 func (p *CSVLookupPlugin) Init(config map[string]string) error {
 	p.csvFile = config["csv_file"]
 	if p.csvFile == "" {
@@ -95,6 +113,63 @@ func (p *CSVLookupPlugin) Resolve(files []api.FileInfo) (api.AssignmentResult, e
 	}, nil
 }
 
+// Group groups the files by the CSV value of their key. Files without a key, or whose key is
+// not in the CSV, are left out of every group.
+func (p *CSVLookupPlugin) Group(results []api.RecertCheckResult) ([]api.Group, error) {
+	var groups []api.Group
+	index := make(map[string]int) // value -> position in groups
+	for _, res := range results {
+		key := p.extractKey([]api.FileInfo{res.File})
+		if key == "" {
+			p.logger.Debug("no key found in file", zap.String("path", res.File.Path))
+			continue
+		}
+		value, exists := p.csvData[key]
+		if !exists {
+			p.logger.Warn("key not found in CSV", zap.String("key", key), zap.String("path", res.File.Path))
+			continue
+		}
+
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, api.Group{Name: value})
+		}
+		groups[i].Files = append(groups[i].Files, res.File)
+	}
+	return groups, nil
+}
+
+// Score multiplies the overdue ratio by the CSV value of the file's key, which must be a number.
+// Files without a key, or whose key is not in the CSV, score their overdue ratio.
+func (p *CSVLookupPlugin) Score(result api.RecertCheckResult) (api.RiskScore, error) {
+	ratio := float64(result.DaysSince) / float64(result.Threshold)
+	score := api.RiskScore{
+		Score:   ratio,
+		Factors: []api.RiskFactor{{Name: "overdue", Detail: fmt.Sprintf("%d/%d days", result.DaysSince, result.Threshold), Factor: ratio}},
+	}
+
+	key := p.extractKey([]api.FileInfo{result.File})
+	if key == "" {
+		p.logger.Debug("no key found in file", zap.String("path", result.File.Path))
+		return score, nil
+	}
+	value, exists := p.csvData[key]
+	if !exists {
+		p.logger.Warn("key not found in CSV", zap.String("key", key), zap.String("path", result.File.Path))
+		return score, nil
+	}
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return api.RiskScore{}, fmt.Errorf("value %q of key %s is not a number: %w", value, key, err)
+	}
+
+	score.Score *= weight
+	score.Factors = append(score.Factors, api.RiskFactor{Name: "csvlookup", Detail: key, Factor: weight})
+	return score, nil
+}
+
 func (p *CSVLookupPlugin) extractKey(files []api.FileInfo) string {
 	for _, file := range files {
 		content, err := p.readFile(file.Path)